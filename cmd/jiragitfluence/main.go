@@ -57,7 +57,7 @@ func Run() error {
 					&cli.StringSliceFlag{
 						Name:     "github-repos",
 						Aliases:  []string{"g"},
-						Usage:    "GitHub repositories to scan (e.g., 'foo/qax-infra')",
+						Usage:    "GitHub repositories to scan (e.g., 'foo/qax-infra', 'org:foo', 'topic:platform org:foo' or a search query like 'is:pr label:roadmap org:foo')",
 						Required: true,
 					},
 					&cli.StringSliceFlag{
@@ -81,6 +81,30 @@ func Run() error {
 						Usage:   "Path to save the raw aggregated data",
 						Value:   "aggregated_data.json",
 					},
+					&cli.DurationFlag{
+						Name:  "max-wait",
+						Usage: "Total time to wait for GitHub rate limit resets before saving a partial result (e.g. '15m', 0 to never wait)",
+					},
+					&cli.BoolFlag{
+						Name:  "enrich-prs",
+						Usage: "Fetch review, CI and commit details for each pull request (one extra API request per PR)",
+					},
+					&cli.BoolFlag{
+						Name:  "search-comments",
+						Usage: "Also apply --github-content-filter to comments, reviews and commit messages",
+					},
+					&cli.StringFlag{
+						Name:  "updated-after",
+						Usage: "Only fetch GitHub issues and PRs updated after this time (RFC3339, YYYY-MM-DD or an age such as 14d)",
+					},
+					&cli.StringFlag{
+						Name:  "closed-after",
+						Usage: "Skip GitHub issues and PRs closed before this time (RFC3339, YYYY-MM-DD or an age such as 14d)",
+					},
+					&cli.BoolFlag{
+						Name:  "allow-truncated",
+						Usage: "Save the first jira.max_issues issues instead of failing when the query matches more",
+					},
 					&cli.StringFlag{
 						Name:    "config",
 						Aliases: []string{"c"},
@@ -116,6 +140,18 @@ func Run() error {
 						Usage:   "Path to save the raw aggregated data",
 						Value:   "jira_data.json",
 					},
+					&cli.BoolFlag{
+						Name:  "allow-truncated",
+						Usage: "Save the first jira.max_issues issues instead of failing when the query matches more",
+					},
+					&cli.BoolFlag{
+						Name:  "incremental",
+						Usage: "Only fetch issues updated since the fetch time of the existing output file and merge them into it",
+					},
+					&cli.StringFlag{
+						Name:  "since",
+						Usage: "Only fetch issues updated since this time (RFC3339, YYYY-MM-DD or an age such as 14d) and merge them into the existing output file",
+					},
 					&cli.StringFlag{
 						Name:    "config",
 						Aliases: []string{"c"},
@@ -130,6 +166,28 @@ func Run() error {
 				},
 				Action: commands.FetchJiraCommand,
 			},
+			{
+				Name:  "jira-fields",
+				Usage: "List Jira fields to help configure jira.field_mapping",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "custom-only",
+						Usage: "Only list custom fields",
+					},
+					&cli.StringFlag{
+						Name:    "config",
+						Aliases: []string{"c"},
+						Usage:   "Path to config file",
+						Value:   "config.yaml",
+					},
+					&cli.BoolFlag{
+						Name:    "verbose",
+						Aliases: []string{"v"},
+						Usage:   "Enable verbose logging",
+					},
+				},
+				Action: commands.JiraFieldsCommand,
+			},
 			{
 				Name:  "fetch-github",
 				Usage: "Fetch data from GitHub only",
@@ -137,7 +195,7 @@ func Run() error {
 					&cli.StringSliceFlag{
 						Name:     "github-repos",
 						Aliases:  []string{"g"},
-						Usage:    "GitHub repositories to scan (e.g., 'foo/qax-infra')",
+						Usage:    "GitHub repositories to scan (e.g., 'foo/qax-infra', 'org:foo', 'topic:platform org:foo' or a search query like 'is:pr label:roadmap org:foo')",
 						Required: true,
 					},
 					&cli.StringSliceFlag{
@@ -161,6 +219,26 @@ func Run() error {
 						Usage:   "Path to save the raw aggregated data",
 						Value:   "github_data.json",
 					},
+					&cli.DurationFlag{
+						Name:  "max-wait",
+						Usage: "Total time to wait for GitHub rate limit resets before saving a partial result (e.g. '15m', 0 to never wait)",
+					},
+					&cli.BoolFlag{
+						Name:  "enrich-prs",
+						Usage: "Fetch review, CI and commit details for each pull request (one extra API request per PR)",
+					},
+					&cli.BoolFlag{
+						Name:  "search-comments",
+						Usage: "Also apply --github-content-filter to comments, reviews and commit messages",
+					},
+					&cli.StringFlag{
+						Name:  "updated-after",
+						Usage: "Only fetch GitHub issues and PRs updated after this time (RFC3339, YYYY-MM-DD or an age such as 14d)",
+					},
+					&cli.StringFlag{
+						Name:  "closed-after",
+						Usage: "Skip GitHub issues and PRs closed before this time (RFC3339, YYYY-MM-DD or an age such as 14d)",
+					},
 					&cli.StringFlag{
 						Name:    "config",
 						Aliases: []string{"c"},
@@ -225,6 +303,22 @@ func Run() error {
 						Aliases: []string{"vl"},
 						Usage:   "Tag to embed in the final content",
 					},
+					&cli.StringSliceFlag{
+						Name:  "attachment",
+						Usage: "Page attachment to show (images) or link (other files), can be repeated",
+					},
+					&cli.StringFlag{
+						Name:  "csv-output",
+						Usage: "Also write the issues and pull requests to this CSV file",
+					},
+					&cli.StringFlag{
+						Name:  "chart-output",
+						Usage: "Also render a chart of items by status to this SVG file",
+					},
+					&cli.StringFlag{
+						Name:  "snapshot-output",
+						Usage: "Also write the aggregated data the page was generated from to this JSON file",
+					},
 					// Roadmap specific options
 					&cli.StringFlag{
 						Name:  "roadmap-timeframe",
@@ -264,16 +358,22 @@ func Run() error {
 						Required: true,
 					},
 					&cli.StringFlag{
-						Name:     "title",
-						Aliases:  []string{"t"},
-						Usage:    "Page title",
-						Required: true,
+						Name:    "title",
+						Aliases: []string{"t"},
+						Usage:   "Page title (required unless --page is given)",
 					},
 					&cli.StringFlag{
-						Name:     "parent",
-						Aliases:  []string{"p"},
-						Usage:    "Parent page title (required for creating pages)",
-						Required: true,
+						Name:  "page",
+						Usage: "Page to update, by ID, URL or title (defaults to the page titled --title)",
+					},
+					&cli.StringFlag{
+						Name:    "parent",
+						Aliases: []string{"p"},
+						Usage:   "Parent page ID, URL or title (required for creating pages)",
+					},
+					&cli.BoolFlag{
+						Name:  "create-parent",
+						Usage: "Create the parent page at the top of the space if it does not exist",
 					},
 					&cli.StringFlag{
 						Name:     "content-file",
@@ -281,6 +381,10 @@ func Run() error {
 						Usage:    "Generated file from the generate command",
 						Required: true,
 					},
+					&cli.StringSliceFlag{
+						Name:  "attach",
+						Usage: "File to attach to the page, updated by filename, can be repeated",
+					},
 					&cli.StringFlag{
 						Name:    "version-comment",
 						Aliases: []string{"vc"},
@@ -291,6 +395,63 @@ func Run() error {
 						Aliases: []string{"a"},
 						Usage:   "Automatically archive older versions",
 					},
+					&cli.StringFlag{
+						Name:  "archive-mode",
+						Usage: "How to archive older versions (prune, snapshot, archive), required with --archive-old-versions",
+					},
+					&cli.IntFlag{
+						Name:  "keep-versions",
+						Usage: "Number of most recent versions to keep when pruning",
+						Value: 10,
+					},
+					&cli.StringFlag{
+						Name:  "archive-older-than",
+						Usage: "Also prune versions older than this date or age (e.g. 2025-01-01, 90d)",
+					},
+					&cli.BoolFlag{
+						Name:  "archive-dry-run",
+						Usage: "List the versions that would be archived or deleted without changing them",
+					},
+					&cli.BoolFlag{
+						Name:  "minor-edit",
+						Usage: "Publish updates as minor edits, without notifying watchers",
+					},
+					&cli.BoolFlag{
+						Name:  "protect-manual-edits",
+						Usage: "Refuse to overwrite the page when someone else edited it last",
+					},
+					&cli.StringFlag{
+						Name:  "region",
+						Usage: "Only replace the marker-delimited region with this name, keeping the rest of the page (overrides config)",
+					},
+					&cli.StringFlag{
+						Name:  "missing-region",
+						Usage: "What to do when the page has no region markers: fail or append (overrides config)",
+					},
+					&cli.StringSliceFlag{
+						Name:  "label",
+						Usage: "Label to add to the page, e.g. auto-generated, a team or a quarter (can be repeated)",
+					},
+					&cli.StringSliceFlag{
+						Name:  "restrict-edit-group",
+						Usage: "Group allowed to edit the page, the publishing account always can (can be repeated)",
+					},
+					&cli.StringSliceFlag{
+						Name:  "restrict-edit-user",
+						Usage: "User allowed to edit the page, an account ID on Cloud (can be repeated)",
+					},
+					&cli.StringSliceFlag{
+						Name:  "property",
+						Usage: "Content property to set on the page as key=value (can be repeated)",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Report whether the page would be created or updated and how its tables would change, without writing anything",
+					},
+					&cli.StringFlag{
+						Name:  "source-data",
+						Usage: "Fetched data file whose metadata (JQL, repositories, fetch time) is stored in the jiragitfluence-source property",
+					},
 					&cli.StringFlag{
 						Name:  "config",
 						Usage: "Path to config file",
@@ -304,6 +465,56 @@ func Run() error {
 				},
 				Action: commands.PublishCommand,
 			},
+			{
+				Name:  "publish-tree",
+				Usage: "Publish a parent page with one child page per content file or group",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "manifest",
+						Aliases:  []string{"m"},
+						Usage:    "Manifest listing the child pages to publish",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:    "space",
+						Aliases: []string{"s"},
+						Usage:   "Confluence space key (overrides the manifest)",
+					},
+					&cli.StringFlag{
+						Name:    "parent",
+						Aliases: []string{"p"},
+						Usage:   "Parent page ID, URL or title (overrides the manifest)",
+					},
+					&cli.StringFlag{
+						Name:    "version-comment",
+						Aliases: []string{"vc"},
+						Usage:   "Comment for Confluence's version control, followed by a summary of the changes",
+					},
+					&cli.BoolFlag{
+						Name:  "archive-stale",
+						Usage: "Archive child pages that are no longer in the manifest",
+					},
+					&cli.BoolFlag{
+						Name:  "minor-edit",
+						Usage: "Publish updates as minor edits, without notifying watchers (overrides the manifest)",
+					},
+					&cli.BoolFlag{
+						Name:  "protect-manual-edits",
+						Usage: "Skip pages that someone else edited last (overrides the manifest and config)",
+					},
+					&cli.StringFlag{
+						Name:  "config",
+						Usage: "Path to config file",
+						Value: "config.yaml",
+					},
+					&cli.BoolFlag{
+						Name:    "verbose",
+						Aliases: []string{"v"},
+						Usage:   "Enable verbose logging",
+					},
+				},
+				Action: commands.PublishTreeCommand,
+			},
 		},
 	}
 
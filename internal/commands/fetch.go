@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/krzko/jiragitfluence/internal/config"
+	"github.com/krzko/jiragitfluence/internal/correlation"
 	"github.com/krzko/jiragitfluence/internal/github"
 	"github.com/krzko/jiragitfluence/internal/jira"
 	"github.com/krzko/jiragitfluence/pkg/models"
@@ -36,6 +37,17 @@ func FetchCommand(ctx *cli.Context) error {
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
 
+	// The command line wait budget takes precedence over the config file
+	if ctx.IsSet("max-wait") {
+		cfg.GitHub.MaxWait = ctx.Duration("max-wait")
+	}
+	if ctx.IsSet("enrich-prs") {
+		cfg.GitHub.EnrichPRs = ctx.Bool("enrich-prs")
+	}
+	if ctx.IsSet("search-comments") {
+		cfg.GitHub.SearchComments = ctx.Bool("search-comments")
+	}
+
 	// Get command line arguments
 	jiraProjects := ctx.StringSlice("jira-projects")
 	jiraJQL := ctx.String("jira-jql")
@@ -45,6 +57,12 @@ func FetchCommand(ctx *cli.Context) error {
 	githubCreator := ctx.String("github-creator")
 	outputPath := ctx.String("output")
 
+	githubDates, err := githubDateFilter(ctx)
+	if err != nil {
+		return err
+	}
+	allowTruncated := ctx.Bool("allow-truncated")
+
 	logger.Info("Starting combined fetch operation",
 		"jira-projects", jiraProjects,
 		"jira-jql", jiraJQL,
@@ -74,11 +92,17 @@ func FetchCommand(ctx *cli.Context) error {
 			return fmt.Errorf("failed to create Jira client: %w", err)
 		}
 
-		jiraIssues, err := jiraClient.FetchIssues(jiraProjects, jiraJQL)
+		result, err := jiraClient.FetchIssues(jiraProjects, jiraJQL, jira.FetchOptions{
+			AllowTruncated: allowTruncated,
+		})
 		if err != nil {
 			return fmt.Errorf("failed to fetch Jira issues: %w", err)
 		}
+		jiraIssues := result.Issues
 		data.JiraIssues = jiraIssues
+		data.Metadata.JiraTotal = result.Total
+		data.Metadata.JiraTruncated = result.Truncated
+		data.Metadata.JiraFullFetchTime = &data.Metadata.FetchTime
 		logger.Info("Fetched Jira issues", 
 			"count", len(jiraIssues), 
 			"projects", jiraProjects, 
@@ -88,21 +112,23 @@ func FetchCommand(ctx *cli.Context) error {
 	// Fetch GitHub issues and PRs if repos are specified
 	if len(githubRepos) > 0 {
 		githubClient := github.NewClient(cfg.GitHub, logger)
-		githubIssues, githubPRs, err := githubClient.FetchIssuesAndPRs(githubRepos, githubLabels, githubContentFilter, githubCreator)
-		if err != nil {
-			return fmt.Errorf("failed to fetch GitHub data: %w", err)
+		if err := fetchGitHubData(logger, githubClient, data, githubRepos, githubLabels, githubContentFilter, githubCreator, githubDates); err != nil {
+			return err
 		}
-		data.GitHubIssues = githubIssues
-		data.GitHubPRs = githubPRs
 		logger.Info("Fetched GitHub data", 
-			"issues", len(githubIssues), 
-			"prs", len(githubPRs), 
-			"repos", githubRepos, 
+			"issues", len(data.GitHubIssues), 
+			"prs", len(data.GitHubPRs), 
+			"repos", data.Metadata.GitHubRepos, 
 			"labels", githubLabels, 
 			"content_filter", githubContentFilter, 
 			"creator", githubCreator)
 	}
 
+	// Link GitHub items to the Jira issues they reference
+	if links := correlation.Link(data); links > 0 {
+		logger.Info("Linked GitHub items to Jira issues", "links", links)
+	}
+
 	// Save aggregated data to file
 	if err := SaveAggregatedData(data, outputPath); err != nil {
 		return fmt.Errorf("failed to save aggregated data: %w", err)
@@ -35,6 +35,17 @@ func FetchGitHubCommand(ctx *cli.Context) error {
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
 
+	// The command line wait budget takes precedence over the config file
+	if ctx.IsSet("max-wait") {
+		cfg.GitHub.MaxWait = ctx.Duration("max-wait")
+	}
+	if ctx.IsSet("enrich-prs") {
+		cfg.GitHub.EnrichPRs = ctx.Bool("enrich-prs")
+	}
+	if ctx.IsSet("search-comments") {
+		cfg.GitHub.SearchComments = ctx.Bool("search-comments")
+	}
+
 	// Get command line arguments
 	githubRepos := ctx.StringSlice("github-repos")
 	githubLabels := ctx.StringSlice("github-labels")
@@ -42,6 +53,11 @@ func FetchGitHubCommand(ctx *cli.Context) error {
 	githubCreator := ctx.String("github-creator")
 	outputPath := ctx.String("output")
 
+	githubDates, err := githubDateFilter(ctx)
+	if err != nil {
+		return err
+	}
+
 	logger.Info("Starting GitHub fetch operation",
 		"github-repos", githubRepos,
 		"github-labels", githubLabels,
@@ -62,16 +78,13 @@ func FetchGitHubCommand(ctx *cli.Context) error {
 
 	// Fetch GitHub issues and PRs
 	githubClient := github.NewClient(cfg.GitHub, logger)
-	githubIssues, githubPRs, err := githubClient.FetchIssuesAndPRs(githubRepos, githubLabels, githubContentFilter, githubCreator)
-	if err != nil {
-		return fmt.Errorf("failed to fetch GitHub data: %w", err)
+	if err := fetchGitHubData(logger, githubClient, data, githubRepos, githubLabels, githubContentFilter, githubCreator, githubDates); err != nil {
+		return err
 	}
-	data.GitHubIssues = githubIssues
-	data.GitHubPRs = githubPRs
 	logger.Info("Fetched GitHub data", 
-		"issues", len(githubIssues), 
-		"prs", len(githubPRs), 
-		"repos", githubRepos, 
+		"issues", len(data.GitHubIssues), 
+		"prs", len(data.GitHubPRs), 
+		"repos", data.Metadata.GitHubRepos, 
 		"labels", githubLabels, 
 		"content_filter", githubContentFilter, 
 		"creator", githubCreator)
@@ -39,10 +39,19 @@ func FetchJiraCommand(ctx *cli.Context) error {
 	jiraProjects := ctx.StringSlice("jira-projects")
 	jiraJQL := ctx.String("jira-jql")
 	outputPath := ctx.String("output")
+	incremental := ctx.Bool("incremental")
+	allowTruncated := ctx.Bool("allow-truncated")
+
+	since, err := parseSince(ctx.String("since"))
+	if err != nil {
+		return err
+	}
 
 	logger.Info("Starting Jira fetch operation",
 		"jira-projects", jiraProjects,
 		"jira-jql", jiraJQL,
+		"incremental", incremental,
+		"since", since,
 		"output", outputPath)
 
 	// Initialize aggregated data
@@ -54,22 +63,81 @@ func FetchJiraCommand(ctx *cli.Context) error {
 		},
 	}
 
+	// Load the existing snapshot when fetching incrementally
+	var existing *models.AggregatedData
+	if incremental || !since.IsZero() {
+		existing, err = loadExistingSnapshot(outputPath)
+		if err != nil {
+			return err
+		}
+		switch {
+		case existing == nil && since.IsZero():
+			logger.Info("No existing snapshot found, performing full fetch", "path", outputPath)
+		case existing == nil:
+			// An explicit --since still only fetches the issues updated since then
+			logger.Warn("No existing snapshot found, saving only the issues updated since the given time", "path", outputPath, "since", since)
+			data.Metadata.JiraUpdatedAfter = &since
+		default:
+			if !sameStrings(existing.Metadata.JiraProjects, jiraProjects) || existing.Metadata.JiraJQL != jiraJQL {
+				logger.Warn("Existing snapshot was fetched with different projects or JQL",
+					"snapshot_projects", existing.Metadata.JiraProjects,
+					"snapshot_jql", existing.Metadata.JiraJQL)
+			}
+			if since.IsZero() {
+				// Overlap the previous fetch to cover clock differences, upserting makes it harmless
+				since = existing.Metadata.FetchTime.Add(-incrementalOverlap)
+			}
+			logger.Info("Fetching Jira issues incrementally",
+				"since", since,
+				"existing_issues", len(existing.JiraIssues))
+		}
+	}
+
 	// Fetch Jira issues
 	jiraClient, err := jira.NewClient(cfg.Jira, logger)
 	if err != nil {
 		return fmt.Errorf("failed to create Jira client: %w", err)
 	}
 
-	jiraIssues, err := jiraClient.FetchIssues(jiraProjects, jiraJQL)
+	result, err := jiraClient.FetchIssues(jiraProjects, jiraJQL, jira.FetchOptions{
+		Since:          since,
+		AllowTruncated: allowTruncated,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to fetch Jira issues: %w", err)
 	}
+	jiraIssues := result.Issues
 	data.JiraIssues = jiraIssues
+	data.Metadata.JiraTotal = result.Total
+	data.Metadata.JiraTruncated = result.Truncated
 	logger.Info("Fetched Jira issues", 
 		"count", len(jiraIssues), 
 		"projects", jiraProjects, 
 		"jql", jiraJQL)
 
+	// Upsert the fetched issues into the existing snapshot
+	if existing != nil {
+		data.JiraIssues = mergeJiraIssues(existing.JiraIssues, jiraIssues)
+		data.Metadata.JiraUpdatedAfter = existing.Metadata.JiraUpdatedAfter
+		// Merging only upserts, issues deleted in Jira or no longer matching the query are kept
+		data.Metadata.JiraUpsertOnly = true
+		data.Metadata.JiraFullFetchTime = existing.Metadata.JiraFullFetchTime
+		logger.Warn("Merged snapshot keeps issues deleted in Jira or no longer matching the query, run without --incremental or --since to replace it",
+			"last_full_fetch", data.Metadata.JiraFullFetchTime)
+		// Issues a truncated fetch missed, now or in the snapshot, are still missing
+		missed := missingIssues(result.Total, len(jiraIssues)) + missingIssues(existing.Metadata.JiraTotal, len(existing.JiraIssues))
+		data.Metadata.JiraTotal = len(data.JiraIssues) + missed
+		data.Metadata.JiraTruncated = missed > 0
+		logger.Info("Merged Jira issues into existing snapshot",
+			"updated", len(jiraIssues),
+			"total", len(data.JiraIssues))
+	}
+
+	// Only a fetch of all matching issues replaces the whole snapshot
+	if existing == nil && since.IsZero() {
+		data.Metadata.JiraFullFetchTime = &data.Metadata.FetchTime
+	}
+
 	// Save aggregated data to file
 	if err := SaveAggregatedData(data, outputPath); err != nil {
 		return fmt.Errorf("failed to save aggregated data: %w", err)
@@ -83,3 +151,11 @@ func FetchJiraCommand(ctx *cli.Context) error {
 }
 
 
+
+// missingIssues returns how many of the matching issues a fetch did not return
+func missingIssues(total, fetched int) int {
+	if total > fetched {
+		return total - fetched
+	}
+	return 0
+}
@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/krzko/jiragitfluence/internal/correlation"
 	"github.com/krzko/jiragitfluence/internal/generator"
 	"github.com/krzko/jiragitfluence/pkg/models"
 	"github.com/urfave/cli/v2"
@@ -32,6 +35,13 @@ func GenerateCommand(ctx *cli.Context) error {
 	groupBy := ctx.String("group-by")
 	includeMetadata := ctx.Bool("include-metadata")
 	versionLabel := ctx.String("version-label")
+	attachments := ctx.StringSlice("attachment")
+	csvOutputPath := ctx.String("csv-output")
+	chartOutputPath := ctx.String("chart-output")
+	snapshotOutputPath := ctx.String("snapshot-output")
+	if chartOutputPath != "" && !strings.EqualFold(filepath.Ext(chartOutputPath), ".svg") {
+		return fmt.Errorf("unsupported chart file %s, charts are rendered as SVG", chartOutputPath)
+	}
 	
 	// Roadmap specific options
 	roadmapTimeframe := ctx.String("roadmap-timeframe")
@@ -83,11 +93,21 @@ func GenerateCommand(ctx *cli.Context) error {
 			// Copy metadata
 			data.Metadata.JiraProjects = jiraData.Metadata.JiraProjects
 			data.Metadata.JiraJQL = jiraData.Metadata.JiraJQL
+			data.Metadata.JiraTotal = jiraData.Metadata.JiraTotal
+			data.Metadata.JiraTruncated = jiraData.Metadata.JiraTruncated
+			data.Metadata.JiraUpdatedAfter = jiraData.Metadata.JiraUpdatedAfter
+			data.Metadata.JiraUpsertOnly = jiraData.Metadata.JiraUpsertOnly
+			data.Metadata.JiraFullFetchTime = jiraData.Metadata.JiraFullFetchTime
 			data.Metadata.FetchTime = jiraData.Metadata.FetchTime
 		} else if inputPath == "" {
 			// We're combining with GitHub data, merge metadata
 			data.Metadata.JiraProjects = jiraData.Metadata.JiraProjects
 			data.Metadata.JiraJQL = jiraData.Metadata.JiraJQL
+			data.Metadata.JiraTotal = jiraData.Metadata.JiraTotal
+			data.Metadata.JiraTruncated = jiraData.Metadata.JiraTruncated
+			data.Metadata.JiraUpdatedAfter = jiraData.Metadata.JiraUpdatedAfter
+			data.Metadata.JiraUpsertOnly = jiraData.Metadata.JiraUpsertOnly
+			data.Metadata.JiraFullFetchTime = jiraData.Metadata.JiraFullFetchTime
 			// Only update fetch time if it's newer or not set
 			if data.Metadata.FetchTime.IsZero() || jiraData.Metadata.FetchTime.After(data.Metadata.FetchTime) {
 				data.Metadata.FetchTime = jiraData.Metadata.FetchTime
@@ -111,16 +131,28 @@ func GenerateCommand(ctx *cli.Context) error {
 		if !dataLoaded {
 			// Copy metadata
 			data.Metadata.GitHubRepos = githubData.Metadata.GitHubRepos
+			data.Metadata.GitHubRepoSpecs = githubData.Metadata.GitHubRepoSpecs
+			data.Metadata.GitHubFailedRepos = githubData.Metadata.GitHubFailedRepos
+			data.Metadata.GitHubPartialRepos = githubData.Metadata.GitHubPartialRepos
+			data.Metadata.GitHubIncomplete = githubData.Metadata.GitHubIncomplete
 			data.Metadata.GitHubLabels = githubData.Metadata.GitHubLabels
 			data.Metadata.GitHubContentFilter = githubData.Metadata.GitHubContentFilter
 			data.Metadata.GitHubCreator = githubData.Metadata.GitHubCreator
+			data.Metadata.GitHubUpdatedAfter = githubData.Metadata.GitHubUpdatedAfter
+			data.Metadata.GitHubClosedAfter = githubData.Metadata.GitHubClosedAfter
 			data.Metadata.FetchTime = githubData.Metadata.FetchTime
 		} else if inputPath == "" {
 			// We're combining with Jira data, merge metadata
 			data.Metadata.GitHubRepos = githubData.Metadata.GitHubRepos
+			data.Metadata.GitHubRepoSpecs = githubData.Metadata.GitHubRepoSpecs
+			data.Metadata.GitHubFailedRepos = githubData.Metadata.GitHubFailedRepos
+			data.Metadata.GitHubPartialRepos = githubData.Metadata.GitHubPartialRepos
+			data.Metadata.GitHubIncomplete = githubData.Metadata.GitHubIncomplete
 			data.Metadata.GitHubLabels = githubData.Metadata.GitHubLabels
 			data.Metadata.GitHubContentFilter = githubData.Metadata.GitHubContentFilter
 			data.Metadata.GitHubCreator = githubData.Metadata.GitHubCreator
+			data.Metadata.GitHubUpdatedAfter = githubData.Metadata.GitHubUpdatedAfter
+			data.Metadata.GitHubClosedAfter = githubData.Metadata.GitHubClosedAfter
 			// Only update fetch time if it's newer or not set
 			if data.Metadata.FetchTime.IsZero() || githubData.Metadata.FetchTime.After(data.Metadata.FetchTime) {
 				data.Metadata.FetchTime = githubData.Metadata.FetchTime
@@ -141,6 +173,12 @@ func GenerateCommand(ctx *cli.Context) error {
 		return fmt.Errorf("no input files specified, please provide either --input, --jira-input, or --github-input")
 	}
 
+	// Link GitHub items to the Jira issues they reference, which also covers
+	// data loaded from separate Jira and GitHub files
+	if links := correlation.Link(data); links > 0 {
+		logger.Info("Linked GitHub items to Jira issues", "links", links)
+	}
+
 	// Log counts of issues and PRs being processed
 	jiraCount := len(data.JiraIssues)
 	githubIssueCount := len(data.GitHubIssues)
@@ -153,6 +191,13 @@ func GenerateCommand(ctx *cli.Context) error {
 		"github_issues", githubIssueCount,
 		"github_prs", githubPRCount)
 
+	// Files written next to the page are referenced from it, publish --attach uploads them
+	for _, path := range []string{chartOutputPath, csvOutputPath, snapshotOutputPath} {
+		if path != "" && !containsAttachment(attachments, path) {
+			attachments = append(attachments, path)
+		}
+	}
+
 	// Create generator
 	gen := generator.NewGenerator(logger)
 
@@ -168,6 +213,8 @@ func GenerateCommand(ctx *cli.Context) error {
 		RoadmapGrouping:     roadmapGrouping,
 		RoadmapView:         generator.RoadmapView(roadmapView),
 		IncludeDependencies: includeDependencies,
+
+		Attachments: attachments,
 	}
 
 	// Generate content
@@ -182,6 +229,30 @@ func GenerateCommand(ctx *cli.Context) error {
 	}
 	logger.Info("Saved generated content", "path", outputPath)
 
+	// Save the CSV export if requested
+	if csvOutputPath != "" {
+		if err := writeCSVFile(data, csvOutputPath); err != nil {
+			return err
+		}
+		logger.Info("Saved CSV export", "path", csvOutputPath)
+	}
+
+	// Save the status chart if requested
+	if chartOutputPath != "" {
+		if err := writeChartFile(data, chartOutputPath); err != nil {
+			return err
+		}
+		logger.Info("Saved status chart", "path", chartOutputPath)
+	}
+
+	// Save the data the page was generated from if requested
+	if snapshotOutputPath != "" {
+		if err := SaveAggregatedData(data, snapshotOutputPath); err != nil {
+			return fmt.Errorf("failed to write data snapshot: %w", err)
+		}
+		logger.Info("Saved data snapshot", "path", snapshotOutputPath)
+	}
+
 	return nil
 }
 
@@ -199,3 +270,45 @@ func loadAggregatedData(inputPath string) (*models.AggregatedData, error) {
 
 	return &aggregatedData, nil
 }
+
+// writeCSVFile writes the CSV export of the aggregated data to a file
+func writeCSVFile(data *models.AggregatedData, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	if err := generator.WriteCSV(file, data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write CSV file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write CSV file: %w", err)
+	}
+	return nil
+}
+
+// writeChartFile writes the SVG status chart of the aggregated data to a file
+func writeChartFile(data *models.AggregatedData, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create chart file: %w", err)
+	}
+	if err := generator.WriteStatusChart(file, data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write chart file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write chart file: %w", err)
+	}
+	return nil
+}
+
+// containsAttachment reports whether a file is already attached, by filename
+func containsAttachment(attachments []string, path string) bool {
+	for _, attachment := range attachments {
+		if filepath.Base(attachment) == filepath.Base(path) {
+			return true
+		}
+	}
+	return false
+}
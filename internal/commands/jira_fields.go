@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	"github.com/krzko/jiragitfluence/internal/config"
+	"github.com/krzko/jiragitfluence/internal/jira"
+	"github.com/urfave/cli/v2"
+)
+
+// JiraFieldsCommand handles the jira-fields command which lists the fields of the Jira instance
+func JiraFieldsCommand(ctx *cli.Context) error {
+	logger := slog.Default()
+
+	// Set log level if verbose flag is set
+	if ctx.Bool("verbose") {
+		logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+			Level: slog.LevelDebug,
+		}))
+		slog.SetDefault(logger)
+	}
+
+	// Load configuration
+	cfg, err := config.LoadConfig(ctx.String("config"))
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Validate configuration
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	jiraClient, err := jira.NewClient(cfg.Jira, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create Jira client: %w", err)
+	}
+
+	fields, err := jiraClient.ListFields()
+	if err != nil {
+		return fmt.Errorf("failed to list Jira fields: %w", err)
+	}
+
+	// Show which roadmap field each Jira field is currently mapped to
+	mappedTo := make(map[string]string)
+	for roadmapField, jiraField := range cfg.Jira.FieldMapping.Fields() {
+		if jiraField != "" {
+			mappedTo[jiraField] = roadmapField
+		}
+	}
+
+	customOnly := ctx.Bool("custom-only")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tTYPE\tMAPPED TO")
+	for _, field := range fields {
+		if customOnly && !field.Custom {
+			continue
+		}
+		mapping := mappedTo[field.ID]
+		if mapping == "" {
+			mapping = mappedTo[field.Name]
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", field.ID, field.Name, field.Type, mapping)
+	}
+
+	return w.Flush()
+}
@@ -1,9 +1,16 @@
 package commands
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/krzko/jiragitfluence/internal/config"
 	"github.com/krzko/jiragitfluence/internal/confluence"
@@ -33,17 +40,84 @@ func PublishCommand(ctx *cli.Context) error {
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
 
+	// Command line flags override the config file
+	if ctx.IsSet("protect-manual-edits") {
+		cfg.Confluence.ProtectManualEdits = ctx.Bool("protect-manual-edits")
+	}
+	if ctx.IsSet("region") {
+		cfg.Confluence.Region = ctx.String("region")
+	}
+	if ctx.IsSet("missing-region") {
+		cfg.Confluence.MissingRegion = ctx.String("missing-region")
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("invalid --missing-region: %w", err)
+		}
+	}
+
 	// Get command line arguments
 	spaceKey := ctx.String("space")
 	title := ctx.String("title")
-	parentTitle := ctx.String("parent")
+	pageRef := ctx.String("page")
+	parentRef := ctx.String("parent")
+	createParent := ctx.Bool("create-parent")
 	contentFilePath := ctx.String("content-file")
 	versionComment := ctx.String("version-comment")
 	archiveOldVersions := ctx.Bool("archive-old-versions")
+	updateOpts := confluence.UpdateOptions{
+		VersionComment:     versionComment,
+		MinorEdit:          ctx.Bool("minor-edit"),
+		ProtectManualEdits: cfg.Confluence.ProtectManualEdits,
+	}
+	var region *confluence.RegionOptions
+	if cfg.Confluence.Region != "" {
+		region = &confluence.RegionOptions{
+			Name:            cfg.Confluence.Region,
+			Markers:         confluence.RegionMarkers(cfg.Confluence.RegionMarkers),
+			AppendIfMissing: cfg.Confluence.MissingRegion == config.MissingRegionAppend,
+		}
+		updateOpts.Region = region
+	}
+
+	olderThan, err := parseTimeFlag("archive-older-than", ctx.String("archive-older-than"))
+	if err != nil {
+		return err
+	}
+	archiveOpts := confluence.ArchiveOptions{
+		Mode:         confluence.ArchiveMode(ctx.String("archive-mode")),
+		KeepVersions: ctx.Int("keep-versions"),
+		OlderThan:    olderThan,
+		DryRun:       ctx.Bool("archive-dry-run"),
+	}
+	if archiveOldVersions {
+		if err := archiveOpts.Validate(); err != nil {
+			return fmt.Errorf("invalid archive options: %w (set --archive-mode)", err)
+		}
+	}
+
+	if title == "" && pageRef == "" {
+		return fmt.Errorf("either --title or --page is required")
+	}
+
+	properties, err := pageProperties(ctx.StringSlice("property"), ctx.String("source-data"))
+	if err != nil {
+		return err
+	}
+	writes := publishWrites{
+		attachments: ctx.StringSlice("attach"),
+		labels:      ctx.StringSlice("label"),
+		restrictions: confluence.EditRestrictions{
+			Groups: ctx.StringSlice("restrict-edit-group"),
+			Users:  ctx.StringSlice("restrict-edit-user"),
+		},
+		properties:  properties,
+		archive:     archiveOldVersions,
+		archiveOpts: archiveOpts,
+	}
 
 	logger.Info("Starting publish operation",
 		"space", spaceKey,
 		"title", title,
+		"page", pageRef,
 		"contentFile", contentFilePath)
 
 	// Read content file
@@ -55,61 +129,438 @@ func PublishCommand(ctx *cli.Context) error {
 	// Create Confluence client
 	confluenceClient := confluence.NewClient(cfg.Confluence, logger)
 
-	// Parent page is required for publishing
-	if parentTitle == "" {
-		return fmt.Errorf("parent page title is required for publishing")
+	// Find the target page, by --page when given and by title otherwise
+	target := confluence.PageLocator{SpaceKey: spaceKey, Title: title}
+	if pageRef != "" {
+		target, err = confluence.ParsePageLocator(pageRef, spaceKey)
+		if err != nil {
+			return err
+		}
 	}
-
-	// Find the parent page ID by title
-	logger.Info("Finding parent page", "space", spaceKey, "parentTitle", parentTitle)
-	parentID, _, err := confluenceClient.FindPage(spaceKey, parentTitle)
-	// Only return an error if we got an error AND no parentID
-	if err != nil && parentID == "" {
-		return fmt.Errorf("failed to find parent page: %w", err)
+	current, err := confluenceClient.ResolvePage(target)
+	if err != nil && !errors.Is(err, confluence.ErrNotFound) {
+		return fmt.Errorf("failed to check if page exists: %w", err)
 	}
-	if parentID == "" {
-		return fmt.Errorf("parent page with title '%s' not found in space '%s'", parentTitle, spaceKey)
+	// Pages given by ID or URL must exist, only titles can be created
+	if current == nil && target.ID != "" {
+		return fmt.Errorf("failed to find %s: %w", target, err)
+	}
+	if title == "" {
+		title = target.Title
+		if current != nil {
+			title = current.Title
+		}
 	}
-	logger.Info("Found parent page", "parentID", parentID, "parentTitle", parentTitle)
 
-	// Check if the target page exists
-	pageID, version, err := confluenceClient.FindPage(spaceKey, title)
-	// Only return an error if we got an error AND no pageID
-	if err != nil && pageID == "" {
-		return fmt.Errorf("failed to check if page exists: %w", err)
+	// Report what would change without writing anything
+	if ctx.Bool("dry-run") {
+		return publishDryRun(confluenceClient, current, spaceKey, title, parentRef, createParent, string(content), updateOpts, writes)
 	}
 
 	var newPageID string
 
 	// Update or create page
-	if pageID != "" {
+	if current != nil {
+		pageID, version := current.ID, current.Version.Number
 		logger.Info("Updating existing page", "pageID", pageID, "version", version)
-		
-		// Archive old versions if requested
-		if archiveOldVersions {
-			if err := confluenceClient.ArchiveOldVersions(pageID); err != nil {
-				logger.Warn("Failed to archive old versions", "error", err)
-				// Continue anyway
-			}
+
+		// Summarise the changes in the version comment, e.g. "3 rows added, 1 status change"
+		updateOpts.DescribeChanges = true
+
+		// Update the page in its own space, which differs from --space for pages found by ID or URL
+		updated, err := confluenceClient.UpdatePage(pageID, current.Space.Key, title, string(content), version, updateOpts)
+		if errors.Is(err, confluence.ErrRegionNotFound) {
+			return fmt.Errorf("failed to update page: %w (add the markers to the page or use --missing-region append)", err)
 		}
-		
-		// Update the page
-		if err := confluenceClient.UpdatePage(pageID, spaceKey, title, string(content), version, versionComment); err != nil {
+		if err != nil {
 			return fmt.Errorf("failed to update page: %w", err)
 		}
-		
+		if !updated {
+			logger.Info("Page is already up to date", "pageID", pageID)
+		}
+
+		// Archive old versions if requested, the page is published either way
+		if writes.archive {
+			if err := archivePreviousVersions(logger, confluenceClient, pageID, current, updated, writes.archiveOpts); err != nil {
+				logger.Warn("Failed to archive old versions", "error", err)
+			}
+		}
+
 		newPageID = pageID
 	} else {
+		// Parent page is required for creating pages
+		if parentRef == "" {
+			return fmt.Errorf("page %q does not exist yet, --parent is required to create it", title)
+		}
+		parentID, err := resolveParentPage(logger, confluenceClient, parentRef, spaceKey, createParent)
+		if err != nil {
+			return err
+		}
+
 		logger.Info("Creating new page", "space", spaceKey, "title", title)
-		
+
+		// New pages get the region markers so later updates keep what is added around them
+		body := string(content)
+		if region != nil {
+			body = confluence.WrapRegion(region.Name, body, region.Markers)
+		}
+
 		// Create the page
-		var err error
-		newPageID, err = confluenceClient.CreatePage(spaceKey, title, string(content), parentID)
+		newPageID, err = confluenceClient.CreatePage(spaceKey, title, body, parentID)
 		if err != nil {
 			return fmt.Errorf("failed to create page: %w", err)
 		}
 	}
 
+	// Upload attachments, the page markup may reference them by filename
+	for _, path := range writes.attachments {
+		if _, err := confluenceClient.AttachFile(newPageID, path, versionComment); err != nil {
+			return fmt.Errorf("failed to attach %s: %w", path, err)
+		}
+	}
+
+	// Labels, restrictions and properties are applied on every run, unchanged pages included
+	if err := confluenceClient.AddLabels(newPageID, writes.labels); err != nil {
+		return fmt.Errorf("failed to label page: %w", err)
+	}
+	if writes.restricted() {
+		if err := confluenceClient.RestrictEditing(newPageID, writes.restrictions); err != nil {
+			return err
+		}
+	}
+	for _, key := range sortedKeys(writes.properties) {
+		if err := confluenceClient.SetContentProperty(newPageID, key, writes.properties[key]); err != nil {
+			return err
+		}
+	}
+
 	logger.Info("Successfully published to Confluence", "pageID", newPageID)
 	return nil
 }
+
+// pageBody returns the body a page gets when content is published to it,
+// merging the generated region into the current body when a region is used
+func pageBody(current *confluence.Page, content string, region *confluence.RegionOptions) (string, error) {
+	if region == nil || current == nil {
+		return content, nil
+	}
+	return confluence.MergeRegion(current.Body.Storage.Value, content, *region)
+}
+
+// publishWrites are the writes publish makes besides creating or updating the page
+type publishWrites struct {
+	attachments  []string
+	labels       []string
+	restrictions confluence.EditRestrictions
+	properties   map[string]interface{}
+	archive      bool
+	archiveOpts  confluence.ArchiveOptions
+}
+
+// restricted reports whether edit restrictions are set
+func (w publishWrites) restricted() bool {
+	return len(w.restrictions.Groups) > 0 || len(w.restrictions.Users) > 0
+}
+
+// publishDryRun reports whether the page would be created or updated, how its
+// tables would change and which other writes publish would make, without
+// writing to Confluence
+func publishDryRun(client *confluence.Client, current *confluence.Page, spaceKey, title, parentRef string, createParent bool, content string, opts confluence.UpdateOptions, writes publishWrites) error {
+	out := os.Stdout
+	fmt.Fprintln(out, "Dry run, nothing is written to Confluence")
+
+	if current == nil {
+		if parentRef == "" {
+			return fmt.Errorf("page %q does not exist yet, --parent is required to create it", title)
+		}
+		loc, err := confluence.ParsePageLocator(parentRef, spaceKey)
+		if err != nil {
+			return fmt.Errorf("invalid parent page: %w", err)
+		}
+		parent, err := client.ResolvePage(loc)
+		switch {
+		case err == nil:
+			fmt.Fprintf(out, "Create: %q in %s under %q (page %s)\n", title, spaceKey, parent.Title, parent.ID)
+		case errors.Is(err, confluence.ErrNotFound) && createParent && loc.ID == "":
+			fmt.Fprintf(out, "Create: parent %q in %s, then %q under it\n", loc.Title, loc.SpaceKey, title)
+		default:
+			return fmt.Errorf("failed to find parent %s: %w", loc, err)
+		}
+
+		body, _ := pageBody(nil, content, opts.Region)
+		confluence.DiffContent("", body).Write(out)
+		return reportPageWrites(client, nil, false, writes)
+	}
+
+	fmt.Fprintf(out, "Update: %q (page %s, version %d) in %s\n", current.Title, current.ID, current.Version.Number, current.Space.Key)
+	if err := reportParent(client, current, spaceKey, parentRef); err != nil {
+		return err
+	}
+	if opts.ProtectManualEdits {
+		if userID, err := client.CurrentUserID(); err == nil && current.Version.AuthorID != "" && current.Version.AuthorID != userID {
+			editor := current.Version.Author
+			if editor == "" {
+				editor = current.Version.AuthorID
+			}
+			fmt.Fprintf(out, "Warning: version %d was saved by %s, publish will refuse to overwrite it\n", current.Version.Number, editor)
+		}
+	}
+
+	body, err := pageBody(current, content, opts.Region)
+	if err != nil {
+		return fmt.Errorf("publish would fail: %w", err)
+	}
+	if current.Title != title {
+		fmt.Fprintf(out, "Title: %q -> %q\n", current.Title, title)
+	}
+	diff := confluence.DiffContent(current.Body.Storage.Value, body)
+	diff.Write(out)
+	if diff.Changed {
+		fmt.Fprintf(out, "Version comment: %s\n", confluence.DescribedVersionComment(opts.VersionComment, confluence.DescribeChange(current.Body.Storage.Value, body)))
+	}
+	return reportPageWrites(client, current, diff.Changed, writes)
+}
+
+// reportParent prints the parent of an existing page. Existing pages are not
+// moved, so a --parent other than the current one is reported as ignored.
+func reportParent(client *confluence.Client, current *confluence.Page, spaceKey, parentRef string) error {
+	out := os.Stdout
+	if current.ParentID != "" {
+		if parent, err := client.GetPage(current.ParentID); err == nil {
+			fmt.Fprintf(out, "Parent: %q (page %s)\n", parent.Title, parent.ID)
+		} else {
+			fmt.Fprintf(out, "Parent: page %s\n", current.ParentID)
+		}
+	}
+	if parentRef == "" {
+		return nil
+	}
+
+	loc, err := confluence.ParsePageLocator(parentRef, spaceKey)
+	if err != nil {
+		return fmt.Errorf("invalid parent page: %w", err)
+	}
+	parent, err := client.ResolvePage(loc)
+	switch {
+	case err == nil && parent.ID == current.ParentID:
+	case err == nil:
+		fmt.Fprintf(out, "Note: the page is not moved under %q (page %s), --parent only applies to new pages\n", parent.Title, parent.ID)
+	case errors.Is(err, confluence.ErrNotFound):
+		fmt.Fprintf(out, "Note: parent %s does not exist, --parent only applies to new pages\n", loc)
+	default:
+		return fmt.Errorf("failed to find parent %s: %w", loc, err)
+	}
+	return nil
+}
+
+// reportPageWrites prints the attachments, labels, restrictions, properties and
+// archiving publish would apply to the page. current is nil for a new page.
+func reportPageWrites(client *confluence.Client, current *confluence.Page, changed bool, writes publishWrites) error {
+	out := os.Stdout
+
+	for _, path := range writes.attachments {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("publish would fail: failed to read attachment: %w", err)
+		}
+		filename := filepath.Base(path)
+		action := "new"
+		if current != nil {
+			existing, err := client.FindAttachment(current.ID, filename)
+			if err != nil {
+				return err
+			}
+			if existing != nil {
+				action = "new version"
+				if existing.FileSize == int64(len(data)) {
+					if old, err := client.DownloadAttachment(existing); err == nil && bytes.Equal(old, data) {
+						action = "unchanged, skipped"
+					}
+				}
+			}
+		}
+		fmt.Fprintf(out, "Attach: %s (%d bytes, %s)\n", filename, len(data), action)
+	}
+
+	// Only labels the page does not have yet are added
+	have := make(map[string]bool)
+	if current != nil && len(writes.labels) > 0 {
+		existing, err := client.GetLabels(current.ID)
+		if err != nil {
+			return err
+		}
+		for _, label := range existing {
+			have[label] = true
+		}
+	}
+	var labels []string
+	for _, label := range writes.labels {
+		if label = confluence.NormalizeLabel(label); label != "" && !have[label] {
+			have[label] = true
+			labels = append(labels, label)
+		}
+	}
+	if len(labels) > 0 {
+		fmt.Fprintf(out, "Labels: add %s\n", strings.Join(labels, ", "))
+	}
+
+	if writes.restricted() {
+		fmt.Fprintf(out, "Restrict editing: groups [%s], users [%s] and the publishing account, replacing the current edit restrictions\n",
+			strings.Join(writes.restrictions.Groups, ", "), strings.Join(writes.restrictions.Users, ", "))
+	}
+
+	for _, key := range sortedKeys(writes.properties) {
+		state, err := propertyState(client, current, key, writes.properties[key])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Property: set %s (%s)\n", key, state)
+	}
+
+	// Only existing pages have versions to archive
+	if !writes.archive || current == nil {
+		return nil
+	}
+	opts := writes.archiveOpts
+	opts.DryRun = true
+	if opts.Mode == confluence.ArchivePrune {
+		pruned, err := client.PruneVersions(current.ID, opts)
+		if err != nil {
+			return fmt.Errorf("failed to list versions to prune: %w", err)
+		}
+		if len(pruned) == 0 {
+			fmt.Fprintln(out, "Archive: no versions to delete")
+		}
+		for _, v := range pruned {
+			fmt.Fprintf(out, "Archive: delete version %d (%s)\n", v.Number, v.When.Format("2006-01-02"))
+		}
+		return nil
+	}
+	if !changed {
+		return nil
+	}
+	snapshot, err := client.SnapshotPage(current, opts)
+	if err != nil {
+		return err
+	}
+	action := "copy"
+	if opts.Mode == confluence.ArchiveCloud {
+		action = "copy and archive"
+	}
+	fmt.Fprintf(out, "Archive: %s version %d as %q under %q\n", action, current.Version.Number, snapshot, confluence.ArchivePageTitle(current.Title))
+	return nil
+}
+
+// propertyState reads back a content property of the page and reports whether
+// setting it to value would create, change or keep it. current is nil for a new page.
+func propertyState(client *confluence.Client, current *confluence.Page, key string, value interface{}) (string, error) {
+	if current == nil {
+		return "new", nil
+	}
+
+	var stored interface{}
+	err := client.GetContentProperty(current.ID, key, &stored)
+	if errors.Is(err, confluence.ErrNotFound) {
+		return "new", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// Compare the values as JSON, the way they are stored
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal property %q: %w", key, err)
+	}
+	var wanted interface{}
+	if err := json.Unmarshal(data, &wanted); err != nil {
+		return "", fmt.Errorf("failed to marshal property %q: %w", key, err)
+	}
+	if reflect.DeepEqual(stored, wanted) {
+		return "unchanged", nil
+	}
+	return "changed", nil
+}
+
+// sourcePropertyKey is the content property holding the metadata of the fetch
+// a page was generated from
+const sourcePropertyKey = "jiragitfluence-source"
+
+// pageProperties builds the content properties to set from key=value pairs and
+// the metadata of the fetched data file the page was generated from
+func pageProperties(pairs []string, sourceData string) (map[string]interface{}, error) {
+	properties := make(map[string]interface{})
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid --property %q, expected key=value", pair)
+		}
+		properties[strings.TrimSpace(key)] = value
+	}
+
+	if sourceData != "" {
+		data, err := loadAggregatedData(sourceData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load source data from %s: %w", sourceData, err)
+		}
+		properties[sourcePropertyKey] = data.Metadata
+	}
+	return properties, nil
+}
+
+// sortedKeys returns the keys of a map in a stable order
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// resolveParentPage finds the parent page given by ID, URL or title. A parent
+// given by title is created at the top of the space when create is set.
+func resolveParentPage(logger *slog.Logger, client *confluence.Client, ref, spaceKey string, create bool) (string, error) {
+	loc, err := confluence.ParsePageLocator(ref, spaceKey)
+	if err != nil {
+		return "", fmt.Errorf("invalid parent page: %w", err)
+	}
+
+	logger.Info("Finding parent page", "parent", loc.String())
+	parent, err := client.ResolvePage(loc)
+	if err == nil {
+		logger.Info("Found parent page", "parentID", parent.ID, "parentTitle", parent.Title)
+		return parent.ID, nil
+	}
+	if !errors.Is(err, confluence.ErrNotFound) || !create || loc.ID != "" {
+		return "", fmt.Errorf("failed to find parent %s: %w", loc, err)
+	}
+
+	logger.Info("Creating parent page", "space", loc.SpaceKey, "title", loc.Title)
+	parentID, err := client.CreatePage(loc.SpaceKey, loc.Title, "<p>Pages published by jiragitfluence.</p>", "")
+	if err != nil {
+		return "", fmt.Errorf("failed to create parent page: %w", err)
+	}
+	return parentID, nil
+}
+
+// archivePreviousVersions prunes the page history, or snapshots the state the
+// page had before this update when it was actually updated
+func archivePreviousVersions(logger *slog.Logger, client *confluence.Client, pageID string, previous *confluence.Page, updated bool, opts confluence.ArchiveOptions) error {
+	if opts.Mode == confluence.ArchivePrune {
+		pruned, err := client.PruneVersions(pageID, opts)
+		if err != nil {
+			return err
+		}
+		if opts.DryRun {
+			logger.Info("Dry run, no versions deleted", "pageID", pageID, "versions", len(pruned))
+		}
+		return nil
+	}
+
+	if !updated || previous == nil {
+		return nil
+	}
+	_, err := client.SnapshotPage(previous, opts)
+	return err
+}
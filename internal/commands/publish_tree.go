@@ -0,0 +1,423 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/krzko/jiragitfluence/internal/config"
+	"github.com/krzko/jiragitfluence/internal/confluence"
+	"github.com/krzko/jiragitfluence/internal/correlation"
+	"github.com/krzko/jiragitfluence/internal/generator"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// treePageLabel marks the child pages managed by publish-tree, so stale pages
+// can be told apart from pages added to the tree by hand
+const treePageLabel = "jiragitfluence-tree"
+
+// TreeManifest describes a page tree to publish
+type TreeManifest struct {
+	Space          string `yaml:"space"`
+	Parent         string `yaml:"parent"`
+	Under          string `yaml:"under"`
+	Index          bool   `yaml:"index"`
+	VersionComment string `yaml:"version_comment"`
+	MinorEdit      bool   `yaml:"minor_edit"`
+	// ProtectManualEdits skips pages last edited by someone else, it is also
+	// enabled by confluence.protect_manual_edits in the config file
+	ProtectManualEdits bool          `yaml:"protect_manual_edits"`
+	Pages              []TreePage    `yaml:"pages"`
+	Generate           *TreeGenerate `yaml:"generate"`
+}
+
+// TreePage is a child page published from a generated content file
+type TreePage struct {
+	Title string `yaml:"title"`
+	File  string `yaml:"file"`
+}
+
+// TreeGenerate generates one child page per team, epic, repository or project
+type TreeGenerate struct {
+	Input           string `yaml:"input"`
+	Format          string `yaml:"format"`
+	GroupBy         string `yaml:"group_by"`
+	SplitBy         string `yaml:"split_by"`
+	Title           string `yaml:"title"`
+	IncludeMetadata bool   `yaml:"include_metadata"`
+}
+
+// treeChild is a child page ready to publish
+type treeChild struct {
+	title   string
+	content string
+}
+
+// PublishTreeCommand handles the publish-tree command
+func PublishTreeCommand(ctx *cli.Context) error {
+	logger := slog.Default()
+
+	// Set log level if verbose flag is set
+	if ctx.Bool("verbose") {
+		logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+			Level: slog.LevelDebug,
+		}))
+		slog.SetDefault(logger)
+	}
+
+	// Load configuration
+	cfg, err := config.LoadConfig(ctx.String("config"))
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Validate configuration
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	manifestPath := ctx.String("manifest")
+	manifest, err := loadTreeManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	// Command line arguments override the manifest
+	if ctx.IsSet("space") {
+		manifest.Space = ctx.String("space")
+	}
+	if ctx.IsSet("parent") {
+		manifest.Parent = ctx.String("parent")
+	}
+	if ctx.IsSet("version-comment") {
+		manifest.VersionComment = ctx.String("version-comment")
+	}
+	if ctx.IsSet("minor-edit") {
+		manifest.MinorEdit = ctx.Bool("minor-edit")
+	}
+	manifest.ProtectManualEdits = manifest.ProtectManualEdits || cfg.Confluence.ProtectManualEdits
+	if ctx.IsSet("protect-manual-edits") {
+		manifest.ProtectManualEdits = ctx.Bool("protect-manual-edits")
+	}
+	if manifest.Space == "" {
+		return fmt.Errorf("space is required, set it in the manifest or with --space")
+	}
+	if manifest.Parent == "" {
+		return fmt.Errorf("parent page title is required, set it in the manifest or with --parent")
+	}
+	archiveStale := ctx.Bool("archive-stale")
+
+	children, err := buildTreeChildren(manifest, filepath.Dir(manifestPath), logger)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Starting publish-tree operation",
+		"space", manifest.Space,
+		"parent", manifest.Parent,
+		"pages", len(children))
+
+	confluenceClient := confluence.NewClient(cfg.Confluence, logger)
+
+	parent, err := ensureTreeParent(confluenceClient, manifest, logger)
+	if err != nil {
+		return err
+	}
+
+	published := make(map[string]bool, len(children))
+	var outside []string
+	for _, child := range children {
+		err := publishTreeChild(confluenceClient, manifest, parent.ID, child, logger)
+		if errors.Is(err, confluence.ErrManualEdit) {
+			// Leave hand-edited pages alone but keep publishing the rest of the tree
+			logger.Warn("Skipping manually edited page", "title", child.title, "error", err)
+		} else if errors.Is(err, errOutsideTree) {
+			// Another page has the title, publish the rest and fail at the end
+			logger.Error("Skipping page outside the tree", "title", child.title, "error", err)
+			outside = append(outside, child.title)
+			continue
+		} else if err != nil {
+			return err
+		}
+		published[child.title] = true
+	}
+
+	if archiveStale {
+		if err := archiveStaleChildren(confluenceClient, manifest, parent, published, logger); err != nil {
+			return err
+		}
+	}
+
+	if manifest.Index {
+		err := publishTreeIndex(confluenceClient, manifest, parent, children)
+		if errors.Is(err, confluence.ErrManualEdit) {
+			logger.Warn("Skipping manually edited index page", "title", parent.Title, "error", err)
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if len(outside) > 0 {
+		return fmt.Errorf("%d pages not published, their titles are used by pages outside the tree: %s", len(outside), strings.Join(outside, ", "))
+	}
+
+	logger.Info("Successfully published page tree to Confluence", "parentID", parent.ID, "pages", len(children))
+	return nil
+}
+
+// loadTreeManifest reads and checks a page tree manifest
+func loadTreeManifest(path string) (*TreeManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var manifest TreeManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	if len(manifest.Pages) == 0 && manifest.Generate == nil {
+		return nil, fmt.Errorf("manifest %s has neither pages nor generate", path)
+	}
+	for i, page := range manifest.Pages {
+		if page.Title == "" || page.File == "" {
+			return nil, fmt.Errorf("manifest page %d needs both a title and a file", i+1)
+		}
+	}
+	if gen := manifest.Generate; gen != nil {
+		if gen.Input == "" || gen.SplitBy == "" {
+			return nil, fmt.Errorf("manifest generate needs both an input and a split_by")
+		}
+		if gen.Title == "" {
+			gen.Title = "{value}"
+		}
+		if gen.Format == "" {
+			gen.Format = string(generator.TableFormat)
+		}
+		if gen.GroupBy == "" {
+			gen.GroupBy = string(generator.StatusGroup)
+		}
+	}
+	return &manifest, nil
+}
+
+// buildTreeChildren reads the listed content files and generates the split pages.
+// Relative paths are resolved against the manifest directory.
+func buildTreeChildren(manifest *TreeManifest, baseDir string, logger *slog.Logger) ([]treeChild, error) {
+	var children []treeChild
+	seen := make(map[string]bool)
+	add := func(child treeChild) error {
+		if seen[child.title] {
+			return fmt.Errorf("duplicate page title in manifest: %s", child.title)
+		}
+		seen[child.title] = true
+		children = append(children, child)
+		return nil
+	}
+
+	for _, page := range manifest.Pages {
+		content, err := os.ReadFile(resolvePath(baseDir, page.File))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read content file for %q: %w", page.Title, err)
+		}
+		if err := add(treeChild{title: page.Title, content: string(content)}); err != nil {
+			return nil, err
+		}
+	}
+
+	if gen := manifest.Generate; gen != nil {
+		data, err := loadAggregatedData(resolvePath(baseDir, gen.Input))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load aggregated data from %s: %w", gen.Input, err)
+		}
+		correlation.Link(data)
+
+		splits, err := generator.SplitData(data, generator.SplitBy(gen.SplitBy))
+		if err != nil {
+			return nil, err
+		}
+
+		g := generator.NewGenerator(logger)
+		for _, split := range splits {
+			content, err := g.Generate(split.Data, generator.Options{
+				Format:          generator.Format(gen.Format),
+				GroupBy:         generator.GroupBy(gen.GroupBy),
+				IncludeMetadata: gen.IncludeMetadata,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate page for %s: %w", split.Value, err)
+			}
+			title := strings.ReplaceAll(gen.Title, "{value}", split.Value)
+			if err := add(treeChild{title: title, content: content}); err != nil {
+				return nil, err
+			}
+		}
+		logger.Info("Generated split pages", "split_by", gen.SplitBy, "pages", len(splits))
+	}
+
+	return children, nil
+}
+
+// resolvePath resolves a manifest path relative to the manifest directory
+func resolvePath(baseDir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
+// ensureTreeParent finds the parent page of the tree, given by ID, URL or title,
+// creating a parent given by title when it does not exist
+func ensureTreeParent(client *confluence.Client, manifest *TreeManifest, logger *slog.Logger) (confluence.PageRef, error) {
+	loc, err := confluence.ParsePageLocator(manifest.Parent, manifest.Space)
+	if err != nil {
+		return confluence.PageRef{}, fmt.Errorf("invalid parent page: %w", err)
+	}
+	parent, err := client.ResolvePage(loc)
+	if err == nil {
+		return confluence.PageRef{ID: parent.ID, Title: parent.Title}, nil
+	}
+	if !errors.Is(err, confluence.ErrNotFound) || loc.ID != "" {
+		return confluence.PageRef{}, fmt.Errorf("failed to find parent %s: %w", loc, err)
+	}
+
+	underID := ""
+	if manifest.Under != "" {
+		underID, err = resolveParentPage(logger, client, manifest.Under, manifest.Space, false)
+		if err != nil {
+			return confluence.PageRef{}, err
+		}
+	}
+
+	logger.Info("Creating parent page", "space", loc.SpaceKey, "title", loc.Title)
+	parentID, err := client.CreatePage(loc.SpaceKey, loc.Title, "<p>Pages published by jiragitfluence.</p>", underID)
+	if err != nil {
+		return confluence.PageRef{}, fmt.Errorf("failed to create parent page: %w", err)
+	}
+	return confluence.PageRef{ID: parentID, Title: loc.Title}, nil
+}
+
+// errOutsideTree means a child page title is taken by a page that is not a child of the tree parent
+var errOutsideTree = errors.New("page with this title is not a child of the tree parent")
+
+// publishTreeChild creates or updates a child page, describing the change in its version comment.
+// Titles are unique within a space, so a page with the child's title elsewhere in the
+// space is never overwritten and an error wrapping errOutsideTree is returned instead.
+func publishTreeChild(client *confluence.Client, manifest *TreeManifest, parentID string, child treeChild, logger *slog.Logger) error {
+	pageID, _, err := client.FindPage(manifest.Space, child.title)
+	if err != nil && !errors.Is(err, confluence.ErrNotFound) {
+		return fmt.Errorf("failed to check if page %q exists: %w", child.title, err)
+	}
+
+	if pageID == "" {
+		logger.Info("Creating child page", "title", child.title)
+		pageID, err = client.CreatePage(manifest.Space, child.title, child.content, parentID)
+		if err != nil {
+			return fmt.Errorf("failed to create page %q: %w", child.title, err)
+		}
+	} else {
+		current, err := client.GetPage(pageID)
+		if err != nil {
+			return err
+		}
+		if current.ParentID != parentID {
+			return fmt.Errorf("page %q (%s) is under page %s: %w", child.title, pageID, current.ParentID, errOutsideTree)
+		}
+		opts := treeUpdateOptions(manifest)
+		updated, err := client.UpdatePage(pageID, manifest.Space, child.title, child.content, current.Version.Number, opts)
+		if err != nil {
+			return fmt.Errorf("failed to update page %q: %w", child.title, err)
+		}
+		if updated {
+			logger.Info("Updated child page", "title", child.title, "pageID", pageID)
+		}
+	}
+
+	// Labelling is idempotent, so pages created before the label existed pick it up too
+	if err := client.AddLabel(pageID, treePageLabel); err != nil {
+		return err
+	}
+	return nil
+}
+
+// archiveStaleChildren archives managed child pages that are no longer in the manifest.
+// Pages are archived where the instance supports it and otherwise moved under an
+// archive page below the parent.
+func archiveStaleChildren(client *confluence.Client, manifest *TreeManifest, parent confluence.PageRef, published map[string]bool, logger *slog.Logger) error {
+	pages, err := client.FindChildPages(parent.ID, treePageLabel)
+	if err != nil {
+		return err
+	}
+
+	archiveID := ""
+	for _, page := range pages {
+		if published[page.Title] {
+			continue
+		}
+
+		logger.Info("Archiving stale child page", "title", page.Title, "pageID", page.ID)
+		err := client.ArchivePage(page.ID)
+		if err == nil {
+			continue
+		}
+		logger.Warn("Archiving not available, moving page instead", "title", page.Title, "error", err)
+
+		if archiveID == "" {
+			archiveID, err = client.EnsureArchivePage(manifest.Space, parent.Title, parent.ID)
+			if err != nil {
+				return err
+			}
+		}
+		if err := client.MovePage(page.ID, archiveID, "Archived by publish-tree: no longer in the manifest"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publishTreeIndex rewrites the parent page with links to its child pages
+func publishTreeIndex(client *confluence.Client, manifest *TreeManifest, parent confluence.PageRef, children []treeChild) error {
+	titles := make([]string, 0, len(children))
+	for _, child := range children {
+		titles = append(titles, child.title)
+	}
+	sort.Strings(titles)
+
+	var content strings.Builder
+	content.WriteString("<ul>\n")
+	for _, title := range titles {
+		fmt.Fprintf(&content, "<li><ac:link><ri:page ri:content-title=\"%s\" /></ac:link></li>\n", escapeAttribute(title))
+	}
+	content.WriteString("</ul>\n")
+
+	current, err := client.GetPage(parent.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := client.UpdatePage(parent.ID, current.Space.Key, parent.Title, content.String(), current.Version.Number, treeUpdateOptions(manifest)); err != nil {
+		return fmt.Errorf("failed to update index page: %w", err)
+	}
+	return nil
+}
+
+// escapeAttribute escapes a value for use in an XML attribute
+func escapeAttribute(value string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\"", "&quot;").Replace(value)
+}
+
+// treeUpdateOptions returns the update options of the pages of a tree, whose version
+// comments summarise what changed (for example "12 lines added, 3 removed")
+func treeUpdateOptions(manifest *TreeManifest) confluence.UpdateOptions {
+	return confluence.UpdateOptions{
+		VersionComment:     manifest.VersionComment,
+		MinorEdit:          manifest.MinorEdit,
+		ProtectManualEdits: manifest.ProtectManualEdits,
+		DescribeChanges:    true,
+	}
+}
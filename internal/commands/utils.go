@@ -2,13 +2,22 @@ package commands
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"strconv"
+	"time"
 
+	"github.com/krzko/jiragitfluence/internal/github"
 	"github.com/krzko/jiragitfluence/pkg/models"
+	"github.com/urfave/cli/v2"
 )
 
+// incrementalOverlap is subtracted from the previous fetch time for incremental fetches,
+// to cover clock differences between this machine and Jira
+const incrementalOverlap = time.Hour
+
 // SaveAggregatedData saves the aggregated data to a JSON file
 func SaveAggregatedData(data *models.AggregatedData, outputPath string) error {
 	// Log counts of issues and PRs
@@ -31,3 +40,202 @@ func SaveAggregatedData(data *models.AggregatedData, outputPath string) error {
 
 	return os.WriteFile(outputPath, jsonData, 0644)
 }
+
+// loadExistingSnapshot loads a previously saved snapshot, returning nil if the file does not exist
+func loadExistingSnapshot(path string) (*models.AggregatedData, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	data, err := loadAggregatedData(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load existing snapshot from %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// mergeJiraIssues upserts updated issues into existing ones by key.
+// Existing issues keep their position, new issues are appended in fetch order.
+func mergeJiraIssues(existing, updated []models.JiraIssue) []models.JiraIssue {
+	index := make(map[string]int, len(existing))
+	merged := make([]models.JiraIssue, len(existing), len(existing)+len(updated))
+	copy(merged, existing)
+	for i, issue := range merged {
+		index[issue.Key] = i
+	}
+
+	for _, issue := range updated {
+		if i, ok := index[issue.Key]; ok {
+			merged[i] = issue
+			continue
+		}
+		index[issue.Key] = len(merged)
+		merged = append(merged, issue)
+	}
+
+	return merged
+}
+
+// parseSince parses a --since value, see parseTimeFlag
+func parseSince(value string) (time.Time, error) {
+	return parseTimeFlag("since", value)
+}
+
+// parseTimeFlag parses a time flag given as RFC3339, YYYY-MM-DD, or as an age
+// relative to now such as 14d, 2w or 36h
+func parseTimeFlag(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	// Days and weeks are not supported by time.ParseDuration
+	if n := len(value) - 1; n > 0 && (value[n] == 'd' || value[n] == 'w') {
+		if count, err := strconv.Atoi(value[:n]); err == nil && count >= 0 {
+			days := count
+			if value[n] == 'w' {
+				days *= 7
+			}
+			return time.Now().AddDate(0, 0, -days), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return time.Now().Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid --%s value %q, expected RFC3339, YYYY-MM-DD or an age such as 14d", name, value)
+}
+
+// githubDateFilter reads the --updated-after and --closed-after flags
+func githubDateFilter(ctx *cli.Context) (github.DateFilter, error) {
+	updatedAfter, err := parseTimeFlag("updated-after", ctx.String("updated-after"))
+	if err != nil {
+		return github.DateFilter{}, err
+	}
+	closedAfter, err := parseTimeFlag("closed-after", ctx.String("closed-after"))
+	if err != nil {
+		return github.DateFilter{}, err
+	}
+	return github.DateFilter{UpdatedAfter: updatedAfter, ClosedAfter: closedAfter}, nil
+}
+
+// sameStrings reports whether two string slices contain the same values in order
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// fetchGitHubData resolves the repository specs, fetches their issues and pull requests,
+// runs any issue search queries and stores the results and fetch metadata in data.
+// Failed repositories and rate limit truncation are recorded instead of failing the fetch.
+func fetchGitHubData(logger *slog.Logger, client *github.Client, data *models.AggregatedData, specs, labels []string, contentFilter, creator string, dates github.DateFilter) error {
+	repos, queries, err := client.ResolveRepos(specs)
+	if err != nil {
+		return fmt.Errorf("failed to resolve GitHub repositories: %w", err)
+	}
+	data.Metadata.GitHubRepoSpecs = specs
+	if !dates.UpdatedAfter.IsZero() {
+		data.Metadata.GitHubUpdatedAfter = &dates.UpdatedAfter
+	}
+	if !dates.ClosedAfter.IsZero() {
+		data.Metadata.GitHubClosedAfter = &dates.ClosedAfter
+	}
+
+	var issues []models.GitHubIssue
+	var prs []models.GitHubPR
+
+	if len(repos) > 0 {
+		repoIssues, repoPRs, err := client.FetchIssuesAndPRs(repos, labels, contentFilter, creator, dates)
+		var fetchErrs github.FetchErrors
+		if errors.As(err, &fetchErrs) {
+			// Keep the repositories that succeeded and record the ones that failed or were cut short
+			logger.Warn("Some GitHub repositories could not be fetched completely", "failed_repos", fetchErrs.Repos(), "partial_repos", fetchErrs.PartialRepos(), "error", err)
+			data.Metadata.GitHubFailedRepos = fetchErrs.Repos()
+			data.Metadata.GitHubPartialRepos = fetchErrs.PartialRepos()
+			data.Metadata.GitHubIncomplete = fetchErrs.RateLimited()
+		} else if err != nil {
+			return fmt.Errorf("failed to fetch GitHub data: %w", err)
+		}
+		issues = append(issues, repoIssues...)
+		prs = append(prs, repoPRs...)
+	}
+
+	if len(queries) > 0 {
+		searchIssues, searchPRs, err := client.SearchIssuesAndPRs(queries, labels, contentFilter, creator, dates)
+		if errors.Is(err, github.ErrWaitBudgetExceeded) {
+			logger.Warn("GitHub search stopped early because of rate limits", "error", err)
+			data.Metadata.GitHubIncomplete = true
+		} else if err != nil {
+			return fmt.Errorf("failed to search GitHub: %w", err)
+		}
+
+		// Search results may overlap with the repositories fetched above
+		seen := make(map[string]bool, len(issues)+len(prs))
+		for _, issue := range issues {
+			seen[issue.URL] = true
+		}
+		for _, pr := range prs {
+			seen[pr.URL] = true
+		}
+		for _, issue := range searchIssues {
+			if !seen[issue.URL] {
+				seen[issue.URL] = true
+				issues = append(issues, issue)
+			}
+		}
+		for _, pr := range searchPRs {
+			if !seen[pr.URL] {
+				seen[pr.URL] = true
+				prs = append(prs, pr)
+			}
+		}
+	}
+
+	// Record the resolved repositories, including those found by search queries
+	resolved := append([]string(nil), repos...)
+	known := make(map[string]bool, len(repos))
+	for _, repo := range repos {
+		known[repo] = true
+	}
+	addRepo := func(repo string) {
+		if !known[repo] {
+			known[repo] = true
+			resolved = append(resolved, repo)
+		}
+	}
+	for _, issue := range issues {
+		addRepo(issue.Repository)
+	}
+	for _, pr := range prs {
+		addRepo(pr.Repository)
+	}
+
+	// Fetch review, CI and commit details once the pull requests are deduplicated
+	if err := client.EnrichPullRequests(prs); errors.Is(err, github.ErrWaitBudgetExceeded) {
+		logger.Warn("GitHub pull request enrichment stopped early because of rate limits", "error", err)
+		data.Metadata.GitHubIncomplete = true
+	} else if err != nil {
+		return fmt.Errorf("failed to enrich GitHub pull requests: %w", err)
+	}
+
+	if err := client.SaveCommentCache(); err != nil {
+		logger.Warn("Failed to save GitHub comment cache", "error", err)
+	}
+
+	data.Metadata.GitHubRepos = resolved
+	data.GitHubIssues = issues
+	data.GitHubPRs = prs
+	return nil
+}
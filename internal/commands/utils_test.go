@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/krzko/jiragitfluence/pkg/models"
+)
+
+func TestParseTimeFlag(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  time.Time
+		// age is the expected distance from now for relative values
+		age     time.Duration
+		wantErr bool
+	}{
+		{name: "empty", value: ""},
+		{name: "RFC3339", value: "2026-10-01T08:30:00Z", want: time.Date(2026, 10, 1, 8, 30, 0, 0, time.UTC)},
+		{name: "RFC3339 with offset", value: "2026-10-01T10:30:00+02:00", want: time.Date(2026, 10, 1, 8, 30, 0, 0, time.UTC)},
+		{name: "date", value: "2026-10-01", want: time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)},
+		{name: "date and time", value: "2026-10-01 08:30", want: time.Date(2026, 10, 1, 8, 30, 0, 0, time.Local)},
+		{name: "days", value: "14d", age: 14 * 24 * time.Hour},
+		{name: "weeks", value: "2w", age: 14 * 24 * time.Hour},
+		{name: "hours", value: "36h", age: 36 * time.Hour},
+		{name: "minutes", value: "90m", age: 90 * time.Minute},
+		{name: "negative age", value: "-1d", wantErr: true},
+		{name: "negative duration", value: "-2h", wantErr: true},
+		{name: "fractional days", value: "1.5d", wantErr: true},
+		{name: "unit only", value: "d", wantErr: true},
+		{name: "day first date", value: "01/10/2026", wantErr: true},
+		{name: "word", value: "yesterday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTimeFlag("since", tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseTimeFlag(%q) = %v, want an error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTimeFlag(%q) error = %v", tt.value, err)
+			}
+
+			if tt.age == 0 {
+				if !got.Equal(tt.want) {
+					t.Errorf("parseTimeFlag(%q) = %v, want %v", tt.value, got, tt.want)
+				}
+				return
+			}
+			// Days are calendar days, allow for a daylight saving change and the test's run time
+			if age := time.Since(got); age < tt.age-time.Hour || age > tt.age+time.Hour {
+				t.Errorf("parseTimeFlag(%q) is %v ago, want %v", tt.value, age, tt.age)
+			}
+		})
+	}
+}
+
+func TestMergeJiraIssues(t *testing.T) {
+	issue := func(key, status string) models.JiraIssue {
+		return models.JiraIssue{Key: key, Status: status}
+	}
+
+	tests := []struct {
+		name     string
+		existing []models.JiraIssue
+		updated  []models.JiraIssue
+		want     []models.JiraIssue
+	}{
+		{
+			name:     "no updates",
+			existing: []models.JiraIssue{issue("A-1", "To Do"), issue("A-2", "To Do")},
+			want:     []models.JiraIssue{issue("A-1", "To Do"), issue("A-2", "To Do")},
+		},
+		{
+			name:     "updated issues keep their position",
+			existing: []models.JiraIssue{issue("A-1", "To Do"), issue("A-2", "To Do"), issue("A-3", "To Do")},
+			updated:  []models.JiraIssue{issue("A-3", "Done"), issue("A-1", "In Progress")},
+			want:     []models.JiraIssue{issue("A-1", "In Progress"), issue("A-2", "To Do"), issue("A-3", "Done")},
+		},
+		{
+			name:     "new issues are appended in order",
+			existing: []models.JiraIssue{issue("A-1", "To Do")},
+			updated:  []models.JiraIssue{issue("A-5", "To Do"), issue("A-1", "Done"), issue("A-4", "To Do")},
+			want:     []models.JiraIssue{issue("A-1", "Done"), issue("A-5", "To Do"), issue("A-4", "To Do")},
+		},
+		{
+			name:    "empty snapshot",
+			updated: []models.JiraIssue{issue("A-1", "To Do")},
+			want:    []models.JiraIssue{issue("A-1", "To Do")},
+		},
+		{
+			name:     "issue updated twice keeps the last update",
+			existing: []models.JiraIssue{issue("A-1", "To Do")},
+			updated:  []models.JiraIssue{issue("A-2", "To Do"), issue("A-2", "Done")},
+			want:     []models.JiraIssue{issue("A-1", "To Do"), issue("A-2", "Done")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := append([]models.JiraIssue(nil), tt.existing...)
+			got := mergeJiraIssues(existing, tt.updated)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeJiraIssues() = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(existing, tt.existing) {
+				t.Errorf("mergeJiraIssues() modified the snapshot: %+v", existing)
+			}
+		})
+	}
+}
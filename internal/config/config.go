@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -17,29 +18,258 @@ type Config struct {
 
 // JiraConfig holds Jira API configuration
 type JiraConfig struct {
-	URL      string `yaml:"url"`
-	Username string `yaml:"username"`
-	APIToken string `yaml:"api_token"`
+	URL          string           `yaml:"url"`
+	Username     string           `yaml:"username"`
+	APIToken     string           `yaml:"api_token"`
+	AuthType     string           `yaml:"auth_type"`
+	PageSize     int              `yaml:"page_size"`
+	MaxIssues    int              `yaml:"max_issues"`
+	FieldMapping JiraFieldMapping `yaml:"field_mapping"`
+	// DependencyLinkTypes lists the issue link type names mapped to dependencies
+	DependencyLinkTypes []string `yaml:"dependency_link_types"`
+}
+
+// Default Jira pagination settings
+const (
+	// DefaultJiraPageSize is the number of issues requested per search page
+	DefaultJiraPageSize = 100
+	// DefaultJiraMaxIssues is the safety limit on the number of issues fetched
+	DefaultJiraMaxIssues = 5000
+)
+
+// JiraFieldMapping maps roadmap fields of models.JiraIssue to Jira fields.
+// Each value may be a field ID (e.g. "customfield_10015" or "duedate") or a field
+// name (e.g. "Start date"), names are resolved against /rest/api/2/field.
+// Entries that are missing or empty leave the roadmap field unmapped.
+type JiraFieldMapping struct {
+	PlannedStartDate string `yaml:"planned_start_date"`
+	PlannedEndDate   string `yaml:"planned_end_date"`
+	Theme            string `yaml:"theme"`
+	Initiative       string `yaml:"initiative"`
+	Dependencies     string `yaml:"dependencies"`
+	PriorityScore    string `yaml:"priority_score"`
+	RoadmapStatus    string `yaml:"roadmap_status"`
+	EpicLink         string `yaml:"epic_link"`
+	Milestone        string `yaml:"milestone"`
+	Quarter          string `yaml:"quarter"`
+	Team             string `yaml:"team"`
+}
+
+// DefaultJiraFieldMapping returns the field mapping used when the config file has no field_mapping section
+func DefaultJiraFieldMapping() JiraFieldMapping {
+	return JiraFieldMapping{
+		PlannedStartDate: "customfield_10001",
+		PlannedEndDate:   "customfield_10002",
+		Theme:            "customfield_10003",
+		Initiative:       "customfield_10004",
+		Dependencies:     "customfield_10005",
+		PriorityScore:    "customfield_10006",
+		RoadmapStatus:    "customfield_10007",
+		EpicLink:         "customfield_10008",
+		Milestone:        "customfield_10009",
+		Quarter:          "customfield_10010",
+		Team:             "team",
+	}
+}
+
+// Fields returns the configured Jira fields keyed by roadmap field name
+func (m JiraFieldMapping) Fields() map[string]string {
+	return map[string]string{
+		"planned_start_date": m.PlannedStartDate,
+		"planned_end_date":   m.PlannedEndDate,
+		"theme":              m.Theme,
+		"initiative":         m.Initiative,
+		"dependencies":       m.Dependencies,
+		"priority_score":     m.PriorityScore,
+		"roadmap_status":     m.RoadmapStatus,
+		"epic_link":          m.EpicLink,
+		"milestone":          m.Milestone,
+		"quarter":            m.Quarter,
+		"team":               m.Team,
+	}
 }
 
 // GitHubConfig holds GitHub API configuration
 type GitHubConfig struct {
 	Token string `yaml:"token"`
+	// Backend selects the API used to fetch issues and pull requests ("rest" or "graphql")
+	Backend string `yaml:"backend"`
+	// ProjectFields maps roadmap fields to Projects v2 field names (graphql backend only)
+	ProjectFields GitHubProjectFields `yaml:"project_fields"`
+	// LabelFields maps roadmap fields to label prefixes such as "theme:"
+	LabelFields GitHubLabelFields `yaml:"label_fields"`
+	// FormFields maps planned dates to issue form field labels
+	FormFields GitHubFormFields `yaml:"form_fields"`
+	// Concurrency is the number of repositories fetched in parallel
+	Concurrency int `yaml:"concurrency"`
+	// FailFast aborts the whole fetch when a single repository fails
+	FailFast bool `yaml:"fail_fast"`
+	// MaxWait is the total time to spend waiting for rate limit resets before
+	// saving a partial result
+	MaxWait time.Duration `yaml:"max_wait"`
+	// EnrichPRs fetches review, CI and commit details for every pull request
+	EnrichPRs bool `yaml:"enrich_prs"`
+	// SearchComments applies the content filter to comments, reviews and commit messages
+	SearchComments bool `yaml:"search_comments"`
+	// CommentConcurrency is the number of timelines fetched in parallel by the comment search
+	CommentConcurrency int `yaml:"comment_concurrency"`
+	// CommentCache is a file caching fetched timelines between runs (in-memory only when empty)
+	CommentCache string `yaml:"comment_cache"`
+	// LabelMatch selects whether items must carry all ("all") or any ("any") of the filter labels
+	LabelMatch string `yaml:"label_match"`
+}
+
+// Supported GitHub label matching modes
+const (
+	// LabelMatchAll keeps items carrying every filter label
+	LabelMatchAll = "all"
+	// LabelMatchAny keeps items carrying at least one filter label
+	LabelMatchAny = "any"
+)
+
+// GitHubLabelFields maps roadmap fields of models.GitHubIssue to label prefixes.
+// A label "theme:Platform" sets the theme to "Platform". An empty prefix turns
+// off parsing labels for that field.
+type GitHubLabelFields struct {
+	Theme    string `yaml:"theme"`
+	Quarter  string `yaml:"quarter"`
+	Priority string `yaml:"priority"`
+}
+
+// DefaultGitHubLabelFields returns the default label prefixes
+func DefaultGitHubLabelFields() GitHubLabelFields {
+	return GitHubLabelFields{
+		Theme:    "theme:",
+		Quarter:  "quarter:",
+		Priority: "priority:",
+	}
 }
 
+// GitHubFormFields maps planned dates of models.GitHubIssue to the labels of issue
+// form fields, which GitHub renders as "### <label>" sections of the issue body
+type GitHubFormFields struct {
+	PlannedStartDate string `yaml:"planned_start_date"`
+	PlannedEndDate   string `yaml:"planned_end_date"`
+}
+
+// GitHubProjectFields maps roadmap fields of models.GitHubIssue to Projects v2 field names
+type GitHubProjectFields struct {
+	PlannedStartDate string `yaml:"planned_start_date"`
+	PlannedEndDate   string `yaml:"planned_end_date"`
+	Milestone        string `yaml:"milestone"`
+	Theme            string `yaml:"theme"`
+}
+
+// Default GitHub fetch settings
+const (
+	// DefaultGitHubConcurrency is the number of repositories fetched in parallel by default
+	DefaultGitHubConcurrency = 4
+	// DefaultGitHubCommentConcurrency is the number of timelines fetched in parallel by default
+	DefaultGitHubCommentConcurrency = 8
+	// DefaultGitHubMaxWait is the default rate limit wait budget
+	DefaultGitHubMaxWait = 15 * time.Minute
+)
+
 // ConfluenceConfig holds Confluence API configuration
 type ConfluenceConfig struct {
 	URL      string `yaml:"url"`
 	Username string `yaml:"username"`
 	APIToken string `yaml:"api_token"`
+	AuthType string `yaml:"auth_type"`
+	// APIVersion selects the REST API used for pages: "v1", "v2" or "auto" to use
+	// v2 where the instance offers it (Confluence Cloud)
+	APIVersion string `yaml:"api_version"`
+	// ProtectManualEdits refuses to update pages last edited by anyone other
+	// than the configured account
+	ProtectManualEdits bool `yaml:"protect_manual_edits"`
+	// Region names the marker-delimited region that publish replaces, keeping
+	// the rest of the page. Empty replaces the whole page
+	Region string `yaml:"region"`
+	// RegionMarkers is the marker style written for new regions: "anchor" or "comment"
+	RegionMarkers string `yaml:"region_markers"`
+	// MissingRegion is what publish does when a page has no region markers:
+	// "fail" or "append" the region to the page
+	MissingRegion string `yaml:"missing_region"`
+}
+
+// Supported Confluence REST API versions
+const (
+	// ConfluenceAPIV1 uses the content API under /rest/api
+	ConfluenceAPIV1 = "v1"
+	// ConfluenceAPIV2 uses the Cloud API under /api/v2
+	ConfluenceAPIV2 = "v2"
+	// ConfluenceAPIAuto uses v2 where the instance offers it and v1 otherwise
+	ConfluenceAPIAuto = "auto"
+)
+
+// Supported region marker styles and missing region behaviours
+const (
+	// RegionMarkersAnchor delimits regions with anchor macros
+	RegionMarkersAnchor = "anchor"
+	// RegionMarkersComment delimits regions with HTML comments
+	RegionMarkersComment = "comment"
+	// MissingRegionFail fails to publish pages without region markers
+	MissingRegionFail = "fail"
+	// MissingRegionAppend appends the region to pages without region markers
+	MissingRegionAppend = "append"
+)
+
+// Supported authentication types for Jira and Confluence
+const (
+	// AuthTypeBearer sends the API token as a Bearer token (Data Center / Server PATs)
+	AuthTypeBearer = "bearer"
+	// AuthTypeBasic sends username and API token using Basic auth (Atlassian Cloud)
+	AuthTypeBasic = "basic"
+)
+
+// ResolveAuthType returns the configured auth type, or picks one based on the host
+// when it is not set: Atlassian Cloud hosts use basic auth, everything else bearer.
+func ResolveAuthType(authType, rawURL string) string {
+	if authType != "" {
+		return strings.ToLower(authType)
+	}
+	if strings.Contains(rawURL, ".atlassian.net") {
+		return AuthTypeBasic
+	}
+	return AuthTypeBearer
+}
+
+// validateAuthType checks that an auth type is supported
+func validateAuthType(field, authType string) error {
+	switch strings.ToLower(authType) {
+	case "", AuthTypeBearer, AuthTypeBasic:
+		return nil
+	default:
+		return fmt.Errorf("unsupported %s: %s (expected %s or %s)", field, authType, AuthTypeBearer, AuthTypeBasic)
+	}
+}
+
+// fieldMappingConfigured reports whether the config file has a jira.field_mapping section
+func fieldMappingConfigured(data []byte) (bool, error) {
+	var probe struct {
+		Jira struct {
+			FieldMapping *yaml.Node `yaml:"field_mapping"`
+		} `yaml:"jira"`
+	}
+	if err := yaml.Unmarshal(data, &probe); err != nil {
+		return false, err
+	}
+	return probe.Jira.FieldMapping != nil, nil
 }
 
 // LoadConfig loads configuration from a YAML file and environment variables
 func LoadConfig(configPath string) (*Config, error) {
-	// Default config
-	config := &Config{}
+	// Default config. The label prefixes and wait budget are set before parsing, so they
+	// keep their defaults when missing from the file while an explicit "" or 0 is kept
+	config := &Config{
+		GitHub: GitHubConfig{
+			LabelFields: DefaultGitHubLabelFields(),
+			MaxWait:     DefaultGitHubMaxWait,
+		},
+	}
 
 	// Load from file if it exists
+	hasFieldMapping := false
 	if _, err := os.Stat(configPath); err == nil {
 		data, err := os.ReadFile(configPath)
 		if err != nil {
@@ -49,11 +279,55 @@ func LoadConfig(configPath string) (*Config, error) {
 		if err := yaml.Unmarshal(data, config); err != nil {
 			return nil, fmt.Errorf("error parsing config file: %w", err)
 		}
+		if hasFieldMapping, err = fieldMappingConfigured(data); err != nil {
+			return nil, fmt.Errorf("error parsing config file: %w", err)
+		}
+	}
+
+	// The default custom field IDs are only used without a field_mapping section,
+	// entries missing from a configured mapping are left unmapped
+	if !hasFieldMapping {
+		config.Jira.FieldMapping = DefaultJiraFieldMapping()
 	}
 
 	// Override with environment variables
 	overrideFromEnv(config)
 
+	// Fall back to the default Jira pagination settings
+	if config.Jira.PageSize <= 0 {
+		config.Jira.PageSize = DefaultJiraPageSize
+	}
+	if config.Jira.MaxIssues <= 0 {
+		config.Jira.MaxIssues = DefaultJiraMaxIssues
+	}
+
+	// Fall back to the default GitHub fetch settings
+	if config.GitHub.Concurrency <= 0 {
+		config.GitHub.Concurrency = DefaultGitHubConcurrency
+	}
+	if config.GitHub.LabelMatch == "" {
+		config.GitHub.LabelMatch = LabelMatchAll
+	}
+	if config.GitHub.CommentConcurrency <= 0 {
+		config.GitHub.CommentConcurrency = DefaultGitHubCommentConcurrency
+	}
+
+	// Detect the Confluence API version unless configured
+	if config.Confluence.APIVersion == "" {
+		config.Confluence.APIVersion = ConfluenceAPIAuto
+	}
+	if config.Confluence.RegionMarkers == "" {
+		config.Confluence.RegionMarkers = RegionMarkersAnchor
+	}
+	if config.Confluence.MissingRegion == "" {
+		config.Confluence.MissingRegion = MissingRegionFail
+	}
+
+	// Only blocker chains are treated as dependencies unless configured otherwise
+	if len(config.Jira.DependencyLinkTypes) == 0 {
+		config.Jira.DependencyLinkTypes = []string{"Blocks"}
+	}
+
 	return config, nil
 }
 
@@ -69,6 +343,9 @@ func overrideFromEnv(config *Config) {
 	if val := os.Getenv("JIRA_API_TOKEN"); val != "" {
 		config.Jira.APIToken = val
 	}
+	if val := os.Getenv("JIRA_AUTH_TYPE"); val != "" {
+		config.Jira.AuthType = val
+	}
 
 	// GitHub config
 	if val := os.Getenv("GITHUB_TOKEN"); val != "" {
@@ -85,6 +362,12 @@ func overrideFromEnv(config *Config) {
 	if val := os.Getenv("CONFLUENCE_API_TOKEN"); val != "" {
 		config.Confluence.APIToken = val
 	}
+	if val := os.Getenv("CONFLUENCE_AUTH_TYPE"); val != "" {
+		config.Confluence.AuthType = val
+	}
+	if val := os.Getenv("CONFLUENCE_API_VERSION"); val != "" {
+		config.Confluence.APIVersion = val
+	}
 }
 
 // Validate checks if the configuration is valid
@@ -122,5 +405,50 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("missing required configuration: %s", strings.Join(missingFields, ", "))
 	}
 
+	// Validate GitHub backend
+	switch strings.ToLower(c.GitHub.Backend) {
+	case "", "rest", "graphql":
+	default:
+		return fmt.Errorf("unsupported github.backend: %s (expected rest or graphql)", c.GitHub.Backend)
+	}
+
+	if c.GitHub.MaxWait < 0 {
+		return fmt.Errorf("invalid github.max_wait: %s (must not be negative)", c.GitHub.MaxWait)
+	}
+
+	// Validate GitHub label matching
+	switch strings.ToLower(c.GitHub.LabelMatch) {
+	case "", LabelMatchAll, LabelMatchAny:
+	default:
+		return fmt.Errorf("unsupported github.label_match: %s (expected %s or %s)", c.GitHub.LabelMatch, LabelMatchAll, LabelMatchAny)
+	}
+
+	// Validate Confluence API version
+	switch strings.ToLower(c.Confluence.APIVersion) {
+	case "", ConfluenceAPIV1, ConfluenceAPIV2, ConfluenceAPIAuto:
+	default:
+		return fmt.Errorf("unsupported confluence.api_version: %s (expected %s, %s or %s)", c.Confluence.APIVersion, ConfluenceAPIV1, ConfluenceAPIV2, ConfluenceAPIAuto)
+	}
+
+	// Validate Confluence region handling
+	switch c.Confluence.RegionMarkers {
+	case "", RegionMarkersAnchor, RegionMarkersComment:
+	default:
+		return fmt.Errorf("unsupported confluence.region_markers: %s (expected %s or %s)", c.Confluence.RegionMarkers, RegionMarkersAnchor, RegionMarkersComment)
+	}
+	switch c.Confluence.MissingRegion {
+	case "", MissingRegionFail, MissingRegionAppend:
+	default:
+		return fmt.Errorf("unsupported confluence.missing_region: %s (expected %s or %s)", c.Confluence.MissingRegion, MissingRegionFail, MissingRegionAppend)
+	}
+
+	// Validate auth types
+	if err := validateAuthType("jira.auth_type", c.Jira.AuthType); err != nil {
+		return err
+	}
+	if err := validateAuthType("confluence.auth_type", c.Confluence.AuthType); err != nil {
+		return err
+	}
+
 	return nil
 }
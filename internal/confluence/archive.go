@@ -0,0 +1,197 @@
+package confluence
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// ArchiveMode selects what happens to the previous state of a page when it is republished
+type ArchiveMode string
+
+const (
+	// ArchivePrune deletes historical versions beyond a keep-count or older than a cutoff
+	ArchivePrune ArchiveMode = "prune"
+	// ArchiveSnapshot copies the previous page to a dated child of an archive page
+	ArchiveSnapshot ArchiveMode = "snapshot"
+	// ArchiveCloud snapshots the previous page and archives the snapshot through
+	// the Confluence Cloud archive, keeping it under the archive page elsewhere
+	ArchiveCloud ArchiveMode = "archive"
+)
+
+// ArchiveOptions configures archiving of previous page versions
+type ArchiveOptions struct {
+	Mode ArchiveMode
+	// KeepVersions is the number of most recent versions kept when pruning
+	KeepVersions int
+	// OlderThan prunes versions last modified before this time, when set
+	OlderThan time.Time
+	// DryRun lists what would be removed or archived without changing anything
+	DryRun bool
+}
+
+// Validate checks that the archive options are usable
+func (o ArchiveOptions) Validate() error {
+	switch o.Mode {
+	case ArchivePrune:
+		if o.KeepVersions < 1 && o.OlderThan.IsZero() {
+			return fmt.Errorf("pruning needs a keep-count of at least 1 or a cutoff date")
+		}
+	case ArchiveSnapshot, ArchiveCloud:
+	case "":
+		// Pruning deletes history, so no mode is picked implicitly
+		return fmt.Errorf("an archive mode is required (prune, snapshot or archive)")
+	default:
+		return fmt.Errorf("unsupported archive mode: %s (supported: prune, snapshot, archive)", o.Mode)
+	}
+	return nil
+}
+
+// PageVersion is an entry in the history of a page
+type PageVersion struct {
+	Number  int
+	When    time.Time
+	Message string
+	By      string
+}
+
+// ListVersions returns the history of a page, newest first
+func (c *Client) ListVersions(pageID string) ([]PageVersion, error) {
+	const limit = 100
+
+	var versions []PageVersion
+	for start := 0; ; start += limit {
+		var result struct {
+			Results []struct {
+				Number  int    `json:"number"`
+				When    string `json:"when"`
+				Message string `json:"message"`
+				By      struct {
+					DisplayName string `json:"displayName"`
+				} `json:"by"`
+			} `json:"results"`
+		}
+		path := fmt.Sprintf("/content/%s/version?start=%d&limit=%d", url.PathEscape(pageID), start, limit)
+		if err := c.rawRequest(http.MethodGet, path, nil, &result); err != nil {
+			return nil, fmt.Errorf("failed to list versions of page %s: %w", pageID, err)
+		}
+
+		for _, r := range result.Results {
+			when, _ := time.Parse(time.RFC3339, r.When)
+			versions = append(versions, PageVersion{Number: r.Number, When: when, Message: r.Message, By: r.By.DisplayName})
+		}
+		if len(result.Results) < limit {
+			break
+		}
+	}
+
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Number > versions[j].Number })
+	return versions, nil
+}
+
+// DeleteVersion deletes a historical version of a page. Confluence renumbers the
+// versions after it, so several versions must be deleted newest first.
+func (c *Client) DeleteVersion(pageID string, number int) error {
+	path := fmt.Sprintf("/content/%s/version/%d", url.PathEscape(pageID), number)
+	if err := c.rawRequest(http.MethodDelete, path, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete version %d of page %s: %w", number, pageID, err)
+	}
+	return nil
+}
+
+// PruneVersions deletes the historical versions of a page that are beyond the
+// keep-count or older than the cutoff. The current version is always kept. It
+// returns the versions deleted, or those that would be deleted on a dry run.
+func (c *Client) PruneVersions(pageID string, opts ArchiveOptions) ([]PageVersion, error) {
+	versions, err := c.ListVersions(pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	var prune []PageVersion
+	for i, v := range versions {
+		if i == 0 {
+			continue
+		}
+		beyondKeep := opts.KeepVersions > 0 && i >= opts.KeepVersions
+		tooOld := !opts.OlderThan.IsZero() && !v.When.IsZero() && v.When.Before(opts.OlderThan)
+		if beyondKeep || tooOld {
+			prune = append(prune, v)
+		}
+	}
+
+	for _, v := range prune {
+		if opts.DryRun {
+			c.logger.Info("Would delete page version", "pageID", pageID, "version", v.Number, "when", v.When, "by", v.By, "message", v.Message)
+			continue
+		}
+		// Versions are sorted newest first, so deleting one does not renumber the rest
+		if err := c.DeleteVersion(pageID, v.Number); err != nil {
+			return nil, err
+		}
+		c.logger.Info("Deleted page version", "pageID", pageID, "version", v.Number, "when", v.When)
+	}
+	return prune, nil
+}
+
+// ArchivePageTitle returns the title of the archive page kept below a page, which
+// holds both its version snapshots and the child pages no longer published
+func ArchivePageTitle(title string) string {
+	return title + " Archive"
+}
+
+// EnsureArchivePage returns the ID of the archive page below a page, creating it when missing
+func (c *Client) EnsureArchivePage(spaceKey, title, pageID string) (string, error) {
+	archiveID, err := c.EnsurePage(spaceKey, ArchivePageTitle(title), "<p>Earlier versions of this page and pages no longer published below it.</p>", pageID)
+	if err != nil {
+		return "", fmt.Errorf("failed to find archive page: %w", err)
+	}
+	return archiveID, nil
+}
+
+// SnapshotPage copies a previous state of a page to a child page of its archive
+// page, see ArchivePageTitle, named with the date of that state. It returns the
+// title of the snapshot page.
+func (c *Client) SnapshotPage(previous *Page, opts ArchiveOptions) (string, error) {
+	when := previous.Version.When
+	if when.IsZero() {
+		when = time.Now()
+	}
+	archiveTitle := ArchivePageTitle(previous.Title)
+	snapshotTitle := fmt.Sprintf("%s (%s)", previous.Title, when.Format("2006-01-02"))
+
+	if opts.DryRun {
+		c.logger.Info("Would archive previous page version", "pageID", previous.ID, "version", previous.Version.Number, "archivePage", archiveTitle, "snapshot", snapshotTitle)
+		return snapshotTitle, nil
+	}
+
+	archiveID, err := c.EnsureArchivePage(previous.Space.Key, previous.Title, previous.ID)
+	if err != nil {
+		return "", err
+	}
+
+	// Titles are unique within a space, so add the time for a second snapshot on the same day
+	if _, _, err := c.FindPage(previous.Space.Key, snapshotTitle); err == nil {
+		snapshotTitle = fmt.Sprintf("%s (%s)", previous.Title, when.Format("2006-01-02 15:04"))
+	} else if !errors.Is(err, ErrNotFound) {
+		return "", err
+	}
+
+	content := fmt.Sprintf("<p><em>Version %d of this page, as of %s.</em></p>\n%s", previous.Version.Number, when.Format(time.RFC1123), previous.Body.Storage.Value)
+	snapshotID, err := c.CreatePage(previous.Space.Key, snapshotTitle, content, archiveID)
+	if err != nil {
+		return "", fmt.Errorf("failed to create snapshot page: %w", err)
+	}
+
+	if opts.Mode == ArchiveCloud {
+		if err := c.ArchivePage(snapshotID); err != nil {
+			c.logger.Warn("Archiving not available, keeping snapshot under the archive page", "snapshot", snapshotTitle, "error", err)
+		}
+	}
+
+	c.logger.Info("Archived previous page version", "pageID", previous.ID, "version", previous.Version.Number, "snapshot", snapshotTitle)
+	return snapshotTitle, nil
+}
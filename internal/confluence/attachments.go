@@ -0,0 +1,177 @@
+package confluence
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Attachment is a file attached to a Confluence page
+type Attachment struct {
+	ID        string
+	Filename  string
+	MediaType string
+	FileSize  int64
+	Download  string
+}
+
+// attachmentResult is an attachment as returned by the REST API
+type attachmentResult struct {
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	Extensions struct {
+		MediaType string `json:"mediaType"`
+		FileSize  int64  `json:"fileSize"`
+	} `json:"extensions"`
+	Links struct {
+		Download string `json:"download"`
+	} `json:"_links"`
+}
+
+// toAttachment converts an attachment REST result
+func (r attachmentResult) toAttachment() Attachment {
+	return Attachment{
+		ID:        r.ID,
+		Filename:  r.Title,
+		MediaType: r.Extensions.MediaType,
+		FileSize:  r.Extensions.FileSize,
+		Download:  r.Links.Download,
+	}
+}
+
+// FindAttachment returns the attachment of a page with the given filename, or nil
+func (c *Client) FindAttachment(pageID, filename string) (*Attachment, error) {
+	var result struct {
+		Results []attachmentResult `json:"results"`
+	}
+	path := fmt.Sprintf("/content/%s/child/attachment?filename=%s", url.PathEscape(pageID), url.QueryEscape(filename))
+	if err := c.rawRequest(http.MethodGet, path, nil, &result); err != nil {
+		return nil, fmt.Errorf("failed to look up attachment %s on page %s: %w", filename, pageID, err)
+	}
+
+	for _, r := range result.Results {
+		if r.Title == filename {
+			attachment := r.toAttachment()
+			return &attachment, nil
+		}
+	}
+	return nil, nil
+}
+
+// DownloadAttachment returns the content of an attachment
+func (c *Client) DownloadAttachment(attachment *Attachment) ([]byte, error) {
+	if c.api == nil {
+		return nil, fmt.Errorf("Confluence API client not initialized")
+	}
+
+	// Download links are relative to the site rather than the REST API
+	siteURL := strings.TrimSuffix(c.baseURL, "/rest/api")
+	req, err := http.NewRequest(http.MethodGet, siteURL+attachment.Download, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	data, err := c.send(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download attachment %s: %w", attachment.Filename, err)
+	}
+	return data, nil
+}
+
+// UploadAttachment attaches data to a page under the given filename. An existing
+// attachment with that filename gets a new version, unless its content is
+// identical, so republishing unchanged files does not grow the page history.
+// It returns whether the attachment was created or updated.
+func (c *Client) UploadAttachment(pageID, filename string, data []byte, comment string) (bool, error) {
+	if c.api == nil {
+		return false, fmt.Errorf("Confluence API client not initialized")
+	}
+
+	existing, err := c.FindAttachment(pageID, filename)
+	if err != nil {
+		return false, err
+	}
+
+	path := fmt.Sprintf("/content/%s/child/attachment", url.PathEscape(pageID))
+	if existing != nil {
+		if existing.FileSize == int64(len(data)) {
+			current, err := c.DownloadAttachment(existing)
+			if err == nil && bytes.Equal(current, data) {
+				c.logger.Info("Attachment unchanged, skipping upload", "pageID", pageID, "filename", filename)
+				return false, nil
+			}
+		}
+		path = fmt.Sprintf("/content/%s/child/attachment/%s/data", url.PathEscape(pageID), url.PathEscape(existing.ID))
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, escapeQuotes(filename)))
+	header.Set("Content-Type", attachmentMediaType(filename))
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return false, fmt.Errorf("failed to create upload: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return false, fmt.Errorf("failed to create upload: %w", err)
+	}
+	// Attachment updates should not notify watchers on their own
+	if err := writer.WriteField("minorEdit", "true"); err != nil {
+		return false, fmt.Errorf("failed to create upload: %w", err)
+	}
+	if comment != "" {
+		if err := writer.WriteField("comment", comment); err != nil {
+			return false, fmt.Errorf("failed to create upload: %w", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return false, fmt.Errorf("failed to create upload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+path, &body)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Accept", "application/json")
+	// Confluence rejects multipart requests without this header as possible XSRF
+	req.Header.Set("X-Atlassian-Token", "no-check")
+
+	if _, err := c.send(req); err != nil {
+		return false, fmt.Errorf("failed to upload attachment %s: %w", filename, err)
+	}
+
+	c.logger.Info("Attachment uploaded", "pageID", pageID, "filename", filename, "updated", existing != nil)
+	return true, nil
+}
+
+// AttachFile attaches a local file to a page under its base name, see UploadAttachment
+func (c *Client) AttachFile(pageID, path, comment string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	return c.UploadAttachment(pageID, filepath.Base(path), data, comment)
+}
+
+// attachmentMediaType returns the media type of a file by its extension
+func attachmentMediaType(filename string) string {
+	if mediaType := mime.TypeByExtension(filepath.Ext(filename)); mediaType != "" {
+		return mediaType
+	}
+	return "application/octet-stream"
+}
+
+// escapeQuotes escapes a value for a quoted MIME header parameter
+func escapeQuotes(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+}
@@ -0,0 +1,58 @@
+package confluence
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/krzko/jiragitfluence/internal/config"
+)
+
+// Backend performs page operations against one version of the Confluence REST API.
+// Errors wrap ErrNotFound, ErrUnauthorized, ErrNetwork or ErrConflict where they apply.
+type Backend interface {
+	// FindPage returns the page with the given title in a space, including its body
+	FindPage(spaceKey, title string) (*Page, error)
+	// GetPage returns a page including its body
+	GetPage(pageID string) (*Page, error)
+	// CreatePage creates a page in page.Space under page.ParentID, if set
+	CreatePage(page *Page) (*Page, error)
+	// UpdatePage stores page as page.Version, moving it under page.ParentID if set
+	UpdatePage(page *Page) error
+	// GetLabels returns the labels of a page
+	GetLabels(pageID string) ([]string, error)
+	// AddLabel adds a global label to a page
+	AddLabel(pageID, label string) error
+}
+
+// newBackend returns the backend for the configured API version, detecting
+// whether the instance offers the v2 API when the version is auto
+func (c *Client) newBackend(apiVersion string) Backend {
+	v1 := &v1Backend{api: c.api}
+	v2 := newV2Backend(c)
+
+	switch apiVersion {
+	case config.ConfluenceAPIV1:
+		return v1
+	case config.ConfluenceAPIV2:
+		return v2
+	}
+
+	// The v2 API only exists on Confluence Cloud, Server and Data Center answer 404
+	if c.api == nil {
+		return v1
+	}
+	req, err := http.NewRequest(http.MethodGet, v2.baseURL+"/spaces?limit=1", nil)
+	if err != nil {
+		return v1
+	}
+	req.Header.Set("Accept", "application/json")
+	if _, err := c.send(req); err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			c.logger.Warn("Failed to detect the Confluence API version, using v1", "error", err)
+		}
+		c.logger.Debug("Using Confluence v1 REST API")
+		return v1
+	}
+	c.logger.Debug("Using Confluence v2 REST API")
+	return v2
+}
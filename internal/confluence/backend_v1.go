@@ -0,0 +1,178 @@
+package confluence
+
+import (
+	"fmt"
+	"time"
+
+	goconfluence "github.com/virtomize/confluence-go-api"
+)
+
+// v1Backend implements Backend with the v1 content API through confluence-go-api
+type v1Backend struct {
+	api *goconfluence.API
+}
+
+// FindPage returns the page with the given title in a space
+func (b *v1Backend) FindPage(spaceKey, title string) (*Page, error) {
+	if b.api == nil {
+		return nil, fmt.Errorf("Confluence API client not initialized")
+	}
+
+	result, err := b.api.GetContent(goconfluence.ContentQuery{
+		Title:    title,
+		SpaceKey: spaceKey,
+		Expand:   []string{"body.storage", "version", "space", "ancestors"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for page %q in space %s: %w", title, spaceKey, classifyError(err))
+	}
+	if result == nil || len(result.Results) == 0 {
+		return nil, fmt.Errorf("page %q in space %s: %w", title, spaceKey, ErrNotFound)
+	}
+	return fromV1Content(&result.Results[0]), nil
+}
+
+// GetPage returns a page including its body
+func (b *v1Backend) GetPage(pageID string) (*Page, error) {
+	if b.api == nil {
+		return nil, fmt.Errorf("Confluence API client not initialized")
+	}
+
+	content, err := b.api.GetContentByID(pageID, goconfluence.ContentQuery{
+		Expand: []string{"body.storage", "version", "space", "ancestors"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page %s: %w", pageID, classifyError(err))
+	}
+	return fromV1Content(content), nil
+}
+
+// CreatePage creates a page
+func (b *v1Backend) CreatePage(page *Page) (*Page, error) {
+	if b.api == nil {
+		return nil, fmt.Errorf("Confluence API client not initialized")
+	}
+
+	created, err := b.api.CreateContent(toV1Content(page))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create page: %w", classifyError(err))
+	}
+	return fromV1Content(created), nil
+}
+
+// UpdatePage stores a new version of a page
+func (b *v1Backend) UpdatePage(page *Page) error {
+	if b.api == nil {
+		return fmt.Errorf("Confluence API client not initialized")
+	}
+
+	if _, err := b.api.UpdateContent(toV1Content(page)); err != nil {
+		return fmt.Errorf("failed to update page: %w", classifyError(err))
+	}
+	return nil
+}
+
+// GetLabels returns the labels of a page
+func (b *v1Backend) GetLabels(pageID string) ([]string, error) {
+	if b.api == nil {
+		return nil, fmt.Errorf("Confluence API client not initialized")
+	}
+
+	result, err := b.api.GetLabels(pageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get labels of page %s: %w", pageID, classifyError(err))
+	}
+	labels := make([]string, 0, len(result.Labels))
+	for _, label := range result.Labels {
+		labels = append(labels, label.Name)
+	}
+	return labels, nil
+}
+
+// AddLabel adds a global label to a page
+func (b *v1Backend) AddLabel(pageID, label string) error {
+	if b.api == nil {
+		return fmt.Errorf("Confluence API client not initialized")
+	}
+
+	labels := []goconfluence.Label{{Prefix: "global", Name: label}}
+	if _, err := b.api.AddLabels(pageID, &labels); err != nil {
+		return fmt.Errorf("failed to add label %q to page %s: %w", label, pageID, classifyError(err))
+	}
+	return nil
+}
+
+// toV1Content converts a page to the confluence-go-api content model
+func toV1Content(page *Page) *goconfluence.Content {
+	content := &goconfluence.Content{
+		ID:    page.ID,
+		Type:  "page",
+		Title: page.Title,
+		Space: &goconfluence.Space{
+			Key: page.Space.Key,
+		},
+		Body: goconfluence.Body{
+			Storage: goconfluence.Storage{
+				Value:          page.Body.Storage.Value,
+				Representation: "storage",
+			},
+		},
+	}
+	if page.ParentID != "" {
+		content.Ancestors = []goconfluence.Ancestor{{ID: page.ParentID}}
+	}
+	if page.Version.Number > 0 {
+		content.Version = &goconfluence.Version{
+			Number:    page.Version.Number,
+			Message:   page.Version.Message,
+			MinorEdit: page.Version.MinorEdit,
+		}
+	}
+	return content
+}
+
+// fromV1Content converts confluence-go-api content to a page
+func fromV1Content(content *goconfluence.Content) *Page {
+	page := &Page{
+		ID:    content.ID,
+		Type:  content.Type,
+		Title: content.Title,
+		Body: Body{
+			Storage: Storage{
+				Value:          content.Body.Storage.Value,
+				Representation: "storage",
+			},
+		},
+	}
+	if content.Space != nil {
+		page.Space = Space{Key: content.Space.Key}
+	}
+	if n := len(content.Ancestors); n > 0 {
+		page.ParentID = content.Ancestors[n-1].ID
+	}
+	if content.Version != nil {
+		page.Version = Version{
+			Number:    content.Version.Number,
+			Message:   content.Version.Message,
+			MinorEdit: content.Version.MinorEdit,
+		}
+		page.Version.When, _ = time.Parse(time.RFC3339, content.Version.When)
+		if by := content.Version.By; by != nil {
+			page.Version.AuthorID = userID(by)
+			page.Version.Author = by.DisplayName
+		}
+	}
+	return page
+}
+
+// userID returns the identifier of a user that is comparable across APIs: the
+// account ID on Cloud, and the user key or name on Server and Data Center
+func userID(user *goconfluence.User) string {
+	switch {
+	case user.AccountID != "":
+		return user.AccountID
+	case user.UserKey != "":
+		return user.UserKey
+	}
+	return user.Username
+}
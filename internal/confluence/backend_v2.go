@@ -0,0 +1,286 @@
+package confluence
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// v2Backend implements Backend with the Confluence Cloud v2 REST API
+type v2Backend struct {
+	client  *Client
+	baseURL string
+
+	mu        sync.Mutex
+	spaceIDs  map[string]string
+	spaceKeys map[string]string
+}
+
+// newV2Backend creates a v2 backend sending requests through the client
+func newV2Backend(c *Client) *v2Backend {
+	// The v2 API lives next to the v1 API, /wiki/rest/api becomes /wiki/api/v2
+	siteURL := strings.TrimSuffix(c.baseURL, "/rest/api")
+	return &v2Backend{
+		client:    c,
+		baseURL:   siteURL + "/api/v2",
+		spaceIDs:  make(map[string]string),
+		spaceKeys: make(map[string]string),
+	}
+}
+
+// v2Page is a page as returned by the v2 API
+type v2Page struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	SpaceID  string `json:"spaceId"`
+	ParentID string `json:"parentId"`
+	Body     struct {
+		Storage *Storage `json:"storage"`
+	} `json:"body"`
+	Version *v2Version `json:"version"`
+}
+
+// v2PageRequest creates or updates a page through the v2 API
+type v2PageRequest struct {
+	ID       string     `json:"id,omitempty"`
+	Status   string     `json:"status"`
+	Title    string     `json:"title"`
+	SpaceID  string     `json:"spaceId,omitempty"`
+	ParentID string     `json:"parentId,omitempty"`
+	Body     Storage    `json:"body"`
+	Version  *v2Version `json:"version,omitempty"`
+}
+
+// v2Version is the version of a page in the v2 API. Updates only take a number
+// and a message, so they cannot be marked as minor edits.
+type v2Version struct {
+	Number    int    `json:"number"`
+	Message   string `json:"message,omitempty"`
+	MinorEdit bool   `json:"minorEdit,omitempty"`
+	CreatedAt string `json:"createdAt,omitempty"`
+	AuthorID  string `json:"authorId,omitempty"`
+}
+
+// v2Space is a space as returned by the v2 API
+type v2Space struct {
+	ID  string `json:"id"`
+	Key string `json:"key"`
+}
+
+// FindPage returns the current page with the given title in a space
+func (b *v2Backend) FindPage(spaceKey, title string) (*Page, error) {
+	spaceID, err := b.spaceID(spaceKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Results []v2Page `json:"results"`
+	}
+	// Archived and trashed pages are returned too unless the status is given, v1 only finds current pages
+	query := url.Values{"space-id": {spaceID}, "title": {title}, "status": {"current"}, "body-format": {"storage"}}
+	if err := b.client.jsonRequest(http.MethodGet, b.baseURL+"/pages?"+query.Encode(), nil, &result); err != nil {
+		return nil, fmt.Errorf("failed to search for page %q in space %s: %w", title, spaceKey, err)
+	}
+	if len(result.Results) == 0 {
+		return nil, fmt.Errorf("page %q in space %s: %w", title, spaceKey, ErrNotFound)
+	}
+	return b.fromV2Page(&result.Results[0])
+}
+
+// GetPage returns a page including its body
+func (b *v2Backend) GetPage(pageID string) (*Page, error) {
+	var page v2Page
+	if err := b.client.jsonRequest(http.MethodGet, b.baseURL+"/pages/"+url.PathEscape(pageID)+"?body-format=storage", nil, &page); err != nil {
+		return nil, fmt.Errorf("failed to get page %s: %w", pageID, err)
+	}
+	return b.fromV2Page(&page)
+}
+
+// CreatePage creates a page
+func (b *v2Backend) CreatePage(page *Page) (*Page, error) {
+	request, err := b.toV2Page(page)
+	if err != nil {
+		return nil, err
+	}
+
+	var created v2Page
+	if err := b.client.jsonRequest(http.MethodPost, b.baseURL+"/pages", request, &created); err != nil {
+		return nil, fmt.Errorf("failed to create page: %w", err)
+	}
+	return b.fromV2Page(&created)
+}
+
+// UpdatePage stores a new version of a page
+func (b *v2Backend) UpdatePage(page *Page) error {
+	request, err := b.toV2Page(page)
+	if err != nil {
+		return err
+	}
+
+	if page.Version.MinorEdit {
+		b.client.logger.Warn("The Confluence v2 API has no minor edits, watchers are notified of this update", "pageID", page.ID)
+	}
+	if err := b.client.jsonRequest(http.MethodPut, b.baseURL+"/pages/"+url.PathEscape(page.ID), request, nil); err != nil {
+		return fmt.Errorf("failed to update page: %w", err)
+	}
+	return nil
+}
+
+// GetLabels returns the labels of a page
+func (b *v2Backend) GetLabels(pageID string) ([]string, error) {
+	var labels []string
+	next := b.baseURL + "/pages/" + url.PathEscape(pageID) + "/labels?limit=250"
+	for next != "" {
+		var result struct {
+			Results []struct {
+				Name string `json:"name"`
+			} `json:"results"`
+			Links struct {
+				Next string `json:"next"`
+			} `json:"_links"`
+		}
+		if err := b.client.jsonRequest(http.MethodGet, next, nil, &result); err != nil {
+			return nil, fmt.Errorf("failed to get labels of page %s: %w", pageID, err)
+		}
+		for _, label := range result.Results {
+			labels = append(labels, label.Name)
+		}
+		next = b.nextURL(result.Links.Next)
+	}
+	return labels, nil
+}
+
+// AddLabel adds a global label to a page. The v2 API cannot change labels, so
+// this uses the v1 label endpoint, which Atlassian has not deprecated.
+func (b *v2Backend) AddLabel(pageID, label string) error {
+	labels := []map[string]string{{"prefix": "global", "name": label}}
+	path := "/content/" + url.PathEscape(pageID) + "/label"
+	if err := b.client.rawRequest(http.MethodPost, path, labels, nil); err != nil {
+		return fmt.Errorf("failed to add label %q to page %s: %w", label, pageID, err)
+	}
+	return nil
+}
+
+// toV2Page converts a page to a v2 create or update request
+func (b *v2Backend) toV2Page(page *Page) (*v2PageRequest, error) {
+	request := &v2PageRequest{
+		ID:       page.ID,
+		Status:   "current",
+		Title:    page.Title,
+		ParentID: page.ParentID,
+		Body:     Storage{Value: page.Body.Storage.Value, Representation: "storage"},
+	}
+
+	spaceID := page.Space.ID
+	if spaceID == "" && page.Space.Key != "" {
+		var err error
+		if spaceID, err = b.spaceID(page.Space.Key); err != nil {
+			return nil, err
+		}
+	}
+	request.SpaceID = spaceID
+
+	if page.Version.Number > 0 {
+		request.Version = &v2Version{Number: page.Version.Number, Message: page.Version.Message}
+	}
+	return request, nil
+}
+
+// fromV2Page converts a v2 page, looking up the key of its space
+func (b *v2Backend) fromV2Page(v2 *v2Page) (*Page, error) {
+	page := &Page{
+		ID:       v2.ID,
+		Type:     "page",
+		Title:    v2.Title,
+		ParentID: v2.ParentID,
+		Space:    Space{ID: v2.SpaceID},
+	}
+	if v2.Body.Storage != nil {
+		page.Body.Storage = *v2.Body.Storage
+	}
+	if v2.Version != nil {
+		page.Version = Version{Number: v2.Version.Number, Message: v2.Version.Message, MinorEdit: v2.Version.MinorEdit, AuthorID: v2.Version.AuthorID}
+		page.Version.When, _ = time.Parse(time.RFC3339, v2.Version.CreatedAt)
+	}
+	if v2.SpaceID != "" {
+		key, err := b.spaceKey(v2.SpaceID)
+		if err != nil {
+			return nil, err
+		}
+		page.Space.Key = key
+	}
+	return page, nil
+}
+
+// spaceID returns the ID of a space by key, which the v2 API uses instead of keys
+func (b *v2Backend) spaceID(spaceKey string) (string, error) {
+	b.mu.Lock()
+	id, ok := b.spaceIDs[spaceKey]
+	b.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	var result struct {
+		Results []v2Space `json:"results"`
+	}
+	if err := b.client.jsonRequest(http.MethodGet, b.baseURL+"/spaces?keys="+url.QueryEscape(spaceKey), nil, &result); err != nil {
+		return "", fmt.Errorf("failed to look up space %s: %w", spaceKey, err)
+	}
+	if len(result.Results) == 0 {
+		return "", fmt.Errorf("space %s: %w", spaceKey, ErrNotFound)
+	}
+
+	b.remember(result.Results[0])
+	return result.Results[0].ID, nil
+}
+
+// spaceKey returns the key of a space by ID
+func (b *v2Backend) spaceKey(spaceID string) (string, error) {
+	b.mu.Lock()
+	key, ok := b.spaceKeys[spaceID]
+	b.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	var space v2Space
+	if err := b.client.jsonRequest(http.MethodGet, b.baseURL+"/spaces/"+url.PathEscape(spaceID), nil, &space); err != nil {
+		return "", fmt.Errorf("failed to look up space %s: %w", spaceID, err)
+	}
+
+	b.remember(space)
+	return space.Key, nil
+}
+
+// remember caches the mapping between a space ID and key
+func (b *v2Backend) remember(space v2Space) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.spaceIDs[space.Key] = space.ID
+	b.spaceKeys[space.ID] = space.Key
+}
+
+// nextURL resolves a pagination link, which the v2 API returns relative to the site
+func (b *v2Backend) nextURL(next string) string {
+	if next == "" {
+		return ""
+	}
+	if strings.HasPrefix(next, "http://") || strings.HasPrefix(next, "https://") {
+		return next
+	}
+	// Links start with /wiki/api/v2 on Cloud, so resolve them against the host
+	base, err := url.Parse(b.baseURL)
+	if err != nil {
+		return ""
+	}
+	ref, err := url.Parse(next)
+	if err != nil {
+		return ""
+	}
+	return base.ResolveReference(ref).String()
+}
@@ -1,10 +1,16 @@
 package confluence
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/krzko/jiragitfluence/internal/config"
 	goconfluence "github.com/virtomize/confluence-go-api"
@@ -12,10 +18,20 @@ import (
 
 // Client handles interactions with the Confluence API
 type Client struct {
-	api    *goconfluence.API
-	logger *slog.Logger
+	api     *goconfluence.API
+	backend Backend
+	baseURL string
+	logger  *slog.Logger
+
+	userOnce sync.Once
+	user     *goconfluence.User
+	userErr  error
 }
 
+// maxUpdateAttempts is the number of times an update is tried when the page
+// keeps changing between reading and writing it
+const maxUpdateAttempts = 3
+
 // NewClient creates a new Confluence client
 func NewClient(cfg config.ConfluenceConfig, logger *slog.Logger) *Client {
 	// Normalize the base URL to ensure it doesn't have a trailing slash
@@ -25,6 +41,9 @@ func NewClient(cfg config.ConfluenceConfig, logger *slog.Logger) *Client {
 	// as they are only used for browser viewing, not API calls
 	baseURL = strings.TrimSuffix(baseURL, "/display")
 
+	// Accept the v2 API URL too, both APIs are derived from the v1 base URL
+	baseURL = strings.TrimSuffix(baseURL, "/api/v2")
+
 	// For Confluence Cloud instances, ensure the URL includes /wiki
 	if strings.Contains(baseURL, ".atlassian.net") && !strings.Contains(baseURL, "/wiki") {
 		baseURL = baseURL + "/wiki"
@@ -35,223 +54,395 @@ func NewClient(cfg config.ConfluenceConfig, logger *slog.Logger) *Client {
 		baseURL = baseURL + "/rest/api"
 	}
 
-	logger.Info("Initializing Confluence client", "baseURL", baseURL)
+	authType := config.ResolveAuthType(cfg.AuthType, cfg.URL)
+	logger.Info("Initializing Confluence client", "baseURL", baseURL, "auth_type", authType)
 
 	// Initialize the confluence-go-api client
-	// For PAT authentication, we use empty username and the token as password
-	api, err := goconfluence.NewAPI(baseURL, "", cfg.APIToken)
+	// For PAT authentication, we use empty username so the token is sent as a Bearer token,
+	// for basic authentication the username (email) and API token are used
+	username := ""
+	if authType == config.AuthTypeBasic {
+		username = cfg.Username
+	}
+	api, err := goconfluence.NewAPI(baseURL, username, cfg.APIToken)
 	if err != nil {
 		logger.Error("Failed to initialize Confluence API client", "error", err)
 		// Return a client with nil API, methods will check and return appropriate errors
 	}
 
-	return &Client{
-		api:    api,
-		logger: logger,
+	c := &Client{
+		api:     api,
+		baseURL: baseURL,
+		logger:  logger,
 	}
+	c.backend = c.newBackend(strings.ToLower(cfg.APIVersion))
+	return c
 }
 
-// FindPage searches for a page by title in a specific space
+// FindPage searches for a page by title in a specific space, returning an error
+// wrapping ErrNotFound when there is no such page
 func (c *Client) FindPage(spaceKey, title string) (string, int, error) {
 	c.logger.Info("Searching for page", "space", spaceKey, "title", title)
 
-	// Check if API client was initialized successfully
-	if c.api == nil {
-		return "", 0, fmt.Errorf("Confluence API client not initialized")
+	page, err := c.backend.FindPage(spaceKey, title)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			c.logger.Info("Page not found", "space", spaceKey, "title", title)
+		} else {
+			c.logger.Error("Failed to search for page", "error", err)
+		}
+		return "", 0, err
 	}
 
-	// Use the ContentQuery to search for content by title and space key
-	query := goconfluence.ContentQuery{
+	c.logger.Info("Found page",
+		"pageID", page.ID,
+		"version", page.Version.Number,
+		"title", page.Title)
+
+	return page.ID, page.Version.Number, nil
+}
+
+// CreatePage creates a new page in Confluence
+func (c *Client) CreatePage(spaceKey, title, content, parentID string) (string, error) {
+	c.logger.Info("Creating new page", "space", spaceKey, "title", title, "parentID", parentID)
+
+	newPage := &Page{
+		Type:     "page",
 		Title:    title,
-		SpaceKey: spaceKey,
-		Expand:   []string{"version"},
+		Space:    Space{Key: spaceKey},
+		ParentID: parentID,
+		Body:     Body{Storage: Storage{Value: content, Representation: "storage"}},
 	}
 
-	c.logger.Debug("Making API request", "title", title, "spaceKey", spaceKey)
-
-	// Execute the search using the confluence-go-api client
-	result, err := c.api.GetContent(query)
+	createdPage, err := c.backend.CreatePage(newPage)
 	if err != nil {
-		c.logger.Error("Failed to search for page", "error", err)
-		
-		// Check if the error message contains useful information about the page
-		// Some Confluence instances return errors but still include the page ID
-		errStr := err.Error()
-		if strings.Contains(errStr, "id") && strings.Contains(errStr, "title") {
-			c.logger.Debug("Error response may contain page information", "error", errStr)
-			
-			// Try to extract the page ID from the error message
-			// This is a fallback mechanism for certain Confluence instances
-			var errorContent struct {
-				Results []struct {
-					ID string `json:"id"`
-				} `json:"results"`
+		c.logger.Error("Failed to create page", "error", err)
+		return "", err
+	}
+
+	c.logger.Info("Page created successfully", "pageID", createdPage.ID, "title", createdPage.Title)
+	return createdPage.ID, nil
+}
+
+// UpdatePage updates an existing page in Confluence. The update is skipped when
+// the title is unchanged and the content only differs from the current body in
+// volatile parts such as the generation timestamp; the result reports whether
+// a new version was created. With Region set, only the generated region of the
+// page is replaced, see MergeRegion.
+//
+// version is the version the caller last saw. The update is based on the page
+// as it is when written, and retried from a fresh read when Confluence reports
+// a version conflict. With ProtectManualEdits, the update is refused with
+// ErrManualEdit when someone other than the publishing account edited last.
+func (c *Client) UpdatePage(pageID, spaceKey, title, content string, version int, opts UpdateOptions) (bool, error) {
+	c.logger.Info("Updating page", "pageID", pageID, "space", spaceKey, "title", title, "version", version)
+
+	for attempt := 1; ; attempt++ {
+		// Fetch the current page to compare against and to base the new version on
+		current, err := c.GetPage(pageID)
+		if err != nil {
+			c.logger.Error("Failed to get current page", "error", err)
+			return false, fmt.Errorf("failed to get current page: %w", err)
+		}
+		if version > 0 && current.Version.Number != version {
+			c.logger.Warn("Page was edited since it was read", "pageID", pageID, "readVersion", version, "currentVersion", current.Version.Number, "editor", editorName(current.Version))
+		}
+
+		if opts.ProtectManualEdits {
+			if err := c.checkLastEditor(current); err != nil {
+				return false, err
 			}
-			
-			if strings.Contains(errStr, "{\"results\"") {
-				// Try to extract JSON from the error message
-				start := strings.Index(errStr, "{")
-				if start >= 0 {
-					jsonStr := errStr[start:]
-					if err := json.Unmarshal([]byte(jsonStr), &errorContent); err == nil && 
-					   len(errorContent.Results) > 0 && errorContent.Results[0].ID != "" {
-						pageID := errorContent.Results[0].ID
-						c.logger.Info("Extracted page ID from error response", "pageID", pageID)
-						return pageID, 0, nil // We don't have version info in this case
-					}
-				}
+		}
+
+		// Only replace the generated region, keeping what was written around it
+		body := content
+		if opts.Region != nil {
+			body, err = MergeRegion(current.Body.Storage.Value, content, *opts.Region)
+			if err != nil {
+				return false, err
 			}
 		}
-		
-		// If we couldn't extract an ID, return an empty ID but no error
-		// This is the expected behavior when checking if a page exists
-		return "", 0, nil
+
+		if current.Title == title && SameContent(current.Body.Storage.Value, body) {
+			c.logger.Info("Page content unchanged, skipping update", "pageID", pageID, "title", title, "version", current.Version.Number)
+			return false, nil
+		}
+
+		// Describe the change against the body this attempt replaces
+		message := opts.VersionComment
+		if opts.DescribeChanges {
+			message = DescribedVersionComment(message, DescribeChange(current.Body.Storage.Value, body))
+		}
+
+		newVersion := current.Version.Number + 1
+		updatePage := &Page{
+			ID:    pageID,
+			Type:  "page",
+			Title: title,
+			Space: Space{Key: spaceKey, ID: current.Space.ID},
+			Body:  Body{Storage: Storage{Value: body, Representation: "storage"}},
+			Version: Version{
+				Number:    newVersion,
+				Message:   message,
+				MinorEdit: opts.MinorEdit,
+			},
+		}
+
+		err = c.backend.UpdatePage(updatePage)
+		if errors.Is(err, ErrConflict) && attempt < maxUpdateAttempts {
+			c.logger.Warn("Page changed while updating, retrying", "pageID", pageID, "attempt", attempt, "error", err)
+			version = 0
+			continue
+		}
+		if err != nil {
+			c.logger.Error("Failed to update page", "error", err)
+			return false, err
+		}
+
+		c.logger.Info("Page updated successfully", "pageID", pageID, "title", title, "newVersion", newVersion, "minorEdit", opts.MinorEdit, "versionComment", message)
+		return true, nil
 	}
+}
 
-	// Check if we have any results
-	if result == nil || len(result.Results) == 0 {
-		c.logger.Info("Page not found", "space", spaceKey, "title", title)
-		return "", 0, nil
+// checkLastEditor returns an error wrapping ErrManualEdit when the current version
+// of a page was written by someone other than the publishing account, or by an
+// editor Confluence does not report
+func (c *Client) checkLastEditor(page *Page) error {
+	userID, err := c.CurrentUserID()
+	if err != nil {
+		return err
 	}
+	if page.Version.AuthorID == "" {
+		return fmt.Errorf("refusing to overwrite version %d of page %q, its editor is unknown: %w", page.Version.Number, page.Title, ErrManualEdit)
+	}
+	if page.Version.AuthorID == userID {
+		return nil
+	}
+	return fmt.Errorf("refusing to overwrite version %d of page %q by %s: %w", page.Version.Number, page.Title, editorName(page.Version), ErrManualEdit)
+}
+
+// currentUser returns the account the client publishes as, looked up once
+func (c *Client) currentUser() (*goconfluence.User, error) {
+	c.userOnce.Do(func() {
+		if c.api == nil {
+			c.userErr = fmt.Errorf("Confluence API client not initialized")
+			return
+		}
+		user, err := c.api.CurrentUser()
+		if err != nil {
+			c.userErr = fmt.Errorf("failed to get the current Confluence user: %w", classifyError(err))
+			return
+		}
+		c.user = user
+	})
+	return c.user, c.userErr
+}
 
-	// Get the first matching page
-	page := result.Results[0]
-	pageID := page.ID
-	versionNumber := 0
-	
-	// Extract version number if available
-	if page.Version != nil {
-		versionNumber = page.Version.Number
+// CurrentUserID returns the identifier of the publishing account, comparable
+// with Version.AuthorID
+func (c *Client) CurrentUserID() (string, error) {
+	user, err := c.currentUser()
+	if err != nil {
+		return "", err
 	}
+	return userID(user), nil
+}
 
-	c.logger.Info("Found page", 
-		"pageID", pageID, 
-		"version", versionNumber, 
-		"title", page.Title)
+// editorName names the editor of a version for messages
+func editorName(version Version) string {
+	if version.Author != "" {
+		return version.Author
+	}
+	if version.AuthorID != "" {
+		return version.AuthorID
+	}
+	return "unknown"
+}
 
-	return pageID, versionNumber, nil
+// GetPage fetches a page including its storage format body
+func (c *Client) GetPage(pageID string) (*Page, error) {
+	return c.backend.GetPage(pageID)
 }
 
-// CreatePage creates a new page in Confluence
-func (c *Client) CreatePage(spaceKey, title, content, parentID string) (string, error) {
-	c.logger.Info("Creating new page", "space", spaceKey, "title", title, "parentID", parentID)
+// PageRef identifies a Confluence page
+type PageRef struct {
+	ID    string
+	Title string
+}
 
-	// Check if API client was initialized successfully
+// FindChildPages returns the direct child pages of a page carrying the given label
+func (c *Client) FindChildPages(parentID, label string) ([]PageRef, error) {
 	if c.api == nil {
-		return "", fmt.Errorf("Confluence API client not initialized")
-	}
-
-	// Create the content object for the new page
-	newPage := &goconfluence.Content{
-		Type:  "page",
-		Title: title,
-		Space: &goconfluence.Space{
-			Key: spaceKey,
-		},
-		Body: goconfluence.Body{
-			Storage: goconfluence.Storage{
-				Value:          content,
-				Representation: "storage",
-			},
-		},
+		return nil, fmt.Errorf("Confluence API client not initialized")
 	}
 
-	// Add parent page reference if provided
-	if parentID != "" {
-		c.logger.Info("Creating as child page", "parentID", parentID)
-		newPage.Ancestors = []goconfluence.Ancestor{
-			{
-				ID: parentID,
-			},
+	const limit = 100
+	cql := fmt.Sprintf("type = page and parent = %s and label = %q", parentID, label)
+
+	var pages []PageRef
+	for start := 0; ; start += limit {
+		result, err := c.api.Search(goconfluence.SearchQuery{CQL: cql, Start: start, Limit: limit})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list child pages of %s: %w", parentID, classifyError(err))
+		}
+		for _, r := range result.Results {
+			ref := PageRef{ID: r.Content.ID, Title: r.Content.Title}
+			if ref.ID == "" {
+				ref.ID = r.ID
+			}
+			if ref.Title == "" {
+				ref.Title = r.Title
+			}
+			pages = append(pages, ref)
+		}
+		if len(result.Results) < limit {
+			break
 		}
 	}
+	return pages, nil
+}
+
+// AddLabel adds a global label to a page
+func (c *Client) AddLabel(pageID, label string) error {
+	return c.backend.AddLabel(pageID, label)
+}
 
-	// Create the page using the API
-	createdPage, err := c.api.CreateContent(newPage)
+// AddLabels adds the labels a page does not have yet. Labels cannot contain
+// spaces, so they are lower cased and spaces replaced with dashes.
+func (c *Client) AddLabels(pageID string, labels []string) error {
+	existing, err := c.GetLabels(pageID)
 	if err != nil {
-		c.logger.Error("Failed to create page", "error", err)
-		return "", fmt.Errorf("failed to create page: %w", err)
+		return err
+	}
+	have := make(map[string]bool, len(existing))
+	for _, label := range existing {
+		have[label] = true
 	}
 
-	c.logger.Info("Page created successfully", "pageID", createdPage.ID, "title", createdPage.Title)
-	return createdPage.ID, nil
+	for _, label := range labels {
+		label = NormalizeLabel(label)
+		if label == "" || have[label] {
+			continue
+		}
+		if err := c.AddLabel(pageID, label); err != nil {
+			return fmt.Errorf("failed to add label %q: %w", label, err)
+		}
+		have[label] = true
+		c.logger.Debug("Label added", "pageID", pageID, "label", label)
+	}
+	return nil
 }
 
-// UpdatePage updates an existing page in Confluence
-func (c *Client) UpdatePage(pageID, spaceKey, title, content string, version int, versionComment string) error {
-	c.logger.Info("Updating page", "pageID", pageID, "space", spaceKey, "title", title, "version", version)
+// NormalizeLabel turns a value such as a team name into a valid label
+func NormalizeLabel(label string) string {
+	return strings.Join(strings.Fields(strings.ToLower(label)), "-")
+}
 
-	// Check if API client was initialized successfully
-	if c.api == nil {
-		return fmt.Errorf("Confluence API client not initialized")
-	}
+// GetLabels returns the labels of a page
+func (c *Client) GetLabels(pageID string) ([]string, error) {
+	return c.backend.GetLabels(pageID)
+}
 
-	// First, check if the page exists
-	_, err := c.api.GetContentByID(pageID, goconfluence.ContentQuery{
-		Expand: []string{"version"},
-	})
+// MovePage moves a page under a new parent, keeping its content
+func (c *Client) MovePage(pageID, parentID, versionComment string) error {
+	page, err := c.GetPage(pageID)
 	if err != nil {
-		c.logger.Error("Failed to get current page", "error", err)
-		return fmt.Errorf("failed to get current page: %w", err)
-	}
-
-	// Create the content object for the page update
-	updatePage := &goconfluence.Content{
-		ID:    pageID,
-		Type:  "page",
-		Title: title,
-		Space: &goconfluence.Space{
-			Key: spaceKey,
-		},
-		Body: goconfluence.Body{
-			Storage: goconfluence.Storage{
-				Value:          content,
-				Representation: "storage",
-			},
-		},
-		Version: &goconfluence.Version{
-			Number:    version + 1,
-			Message:   versionComment,
-			MinorEdit: false,
-		},
+		return err
+	}
+
+	page.ParentID = parentID
+	page.Version = Version{
+		Number:    page.Version.Number + 1,
+		Message:   versionComment,
+		MinorEdit: true,
 	}
+	if err := c.backend.UpdatePage(page); err != nil {
+		return fmt.Errorf("failed to move page %s: %w", pageID, err)
+	}
+
+	c.logger.Info("Page moved", "pageID", pageID, "parentID", parentID)
+	return nil
+}
 
-	// Update the page using the API
-	_, err = c.api.UpdateContent(updatePage)
+// ArchivePage archives a page through the Confluence Cloud archive endpoint.
+// Instances without the endpoint, such as Confluence Server and Data Center,
+// report an error and the caller decides on a fallback.
+func (c *Client) ArchivePage(pageID string) error {
+	id, err := strconv.ParseInt(pageID, 10, 64)
 	if err != nil {
-		c.logger.Error("Failed to update page", "error", err)
-		return fmt.Errorf("failed to update page: %w", err)
+		return fmt.Errorf("invalid page ID %q: %w", pageID, err)
+	}
+
+	body := map[string]interface{}{
+		"pages": []map[string]int64{{"id": id}},
+	}
+	if err := c.rawRequest(http.MethodPost, "/content/archive", body, nil); err != nil {
+		return fmt.Errorf("failed to archive page %s: %w", pageID, err)
 	}
 
-	c.logger.Info("Page updated successfully", "pageID", pageID, "title", title, "newVersion", version+1)
+	c.logger.Info("Page archived", "pageID", pageID)
 	return nil
 }
 
-// ArchiveOldVersions archives older versions of a page by adding an "Archived" label
-// This is a simplified implementation - actual archiving would depend on your specific requirements
-func (c *Client) ArchiveOldVersions(pageID string) error {
-	c.logger.Info("Archiving old versions", "pageID", pageID)
+// rawRequest sends a request to an endpoint the confluence-go-api package does not
+// cover, relative to the REST API base URL, and decodes a JSON response into out
+func (c *Client) rawRequest(method, path string, body interface{}, out interface{}) error {
+	return c.jsonRequest(method, c.baseURL+path, body, out)
+}
 
-	// Check if API client was initialized successfully
+// jsonRequest sends a request with an optional JSON body to a URL and decodes a
+// JSON response into out
+func (c *Client) jsonRequest(method, endpoint string, body interface{}, out interface{}) error {
 	if c.api == nil {
 		return fmt.Errorf("Confluence API client not initialized")
 	}
 
-	// Add an "Archived" label to the page
-	// This is a simple way to mark pages as archived
-	labels := []goconfluence.Label{
-		{
-			Prefix: "global",
-			Name:   "Archived",
-		},
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
 	}
 
-	_, err := c.api.AddLabels(pageID, &labels)
+	req, err := http.NewRequest(method, endpoint, reader)
 	if err != nil {
-		c.logger.Error("Failed to add archive label", "error", err)
-		return fmt.Errorf("failed to archive page: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
 
-	c.logger.Info("Page archived successfully", "pageID", pageID)
+	data, err := c.send(req)
+	if err != nil {
+		return err
+	}
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
 	return nil
 }
+
+// send authenticates and sends a request, returning the response body of a successful response
+func (c *Client) send(req *http.Request) ([]byte, error) {
+	c.api.Auth(req)
+
+	resp, err := c.api.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNetwork, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, statusError(resp.StatusCode, resp.Status)
+	}
+	return data, nil
+}
@@ -0,0 +1,45 @@
+package confluence
+
+import (
+	"regexp"
+	"strings"
+)
+
+// volatilePatterns match the parts of generated content that change on every run
+// without the report changing: the generation timestamp and the fetch time
+var volatilePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(<strong>Generated:</strong>)[^<]*`),
+	regexp.MustCompile(`(>Fetch Time</td>\s*<td[^>]*>)[^<]*`),
+}
+
+// betweenTags matches whitespace between tags, which Confluence may reformat
+var betweenTags = regexp.MustCompile(`>\s+<`)
+
+// selfClosingSpace matches the space Confluence drops or adds before "/>"
+var selfClosingSpace = regexp.MustCompile(`\s+/>`)
+
+// storedAttributes matches the attributes Confluence adds to macros and tables when it
+// stores a page, such as the macro ID and schema version of every structured macro
+var storedAttributes = regexp.MustCompile(`\s+(?:ac:macro-id|ac:schema-version|ac:local-id|local-id|data-layout|data-table-width)="[^"]*"`)
+
+// tableBody matches the tbody tags Confluence wraps table rows in
+var tableBody = regexp.MustCompile(`</?tbody[^>]*>`)
+
+// NormalizeContent reduces storage format content to a form that is stable between
+// runs and across the reformatting Confluence applies when it stores a page
+func NormalizeContent(content string) string {
+	for _, pattern := range volatilePatterns {
+		content = pattern.ReplaceAllString(content, "$1")
+	}
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = storedAttributes.ReplaceAllString(content, "")
+	content = tableBody.ReplaceAllString(content, "")
+	content = betweenTags.ReplaceAllString(content, "><")
+	content = selfClosingSpace.ReplaceAllString(content, "/>")
+	return strings.Join(strings.Fields(content), " ")
+}
+
+// SameContent reports whether two storage format bodies only differ in volatile parts
+func SameContent(a, b string) bool {
+	return NormalizeContent(a) == NormalizeContent(b)
+}
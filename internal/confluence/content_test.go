@@ -0,0 +1,84 @@
+package confluence
+
+import (
+	"strings"
+	"testing"
+)
+
+// generatedBody is a page body as the generator renders it
+const generatedBody = `<h1>Status Report</h1>
+<p><strong>Generated:</strong> Mon, 12 Oct 2026 09:00:00 UTC</p>
+
+<ac:structured-macro ac:name="info">
+<ac:rich-text-body>
+<p><strong>Summary</strong></p>
+<ul>
+<li>Jira Issues: 2</li>
+</ul>
+</ac:rich-text-body>
+</ac:structured-macro>
+
+<h2>Jira Issues</h2>
+<table>
+<tr><th>Key</th><th>Summary</th><th>Status</th></tr>
+<tr><td>PROJ-1</td><td>Add audit log export</td><td>In Progress</td></tr>
+<tr><td>PROJ-2</td><td>Rotate signing keys</td><td>To Do</td></tr>
+</table>
+`
+
+// storedBody is generatedBody as Confluence returns it after storing the page an
+// hour earlier, with macro IDs, schema versions and table layout attributes added
+const storedBody = `<h1>Status Report</h1><p><strong>Generated:</strong> Mon, 12 Oct 2026 08:00:00 UTC</p><ac:structured-macro ac:name="info" ac:schema-version="1" ac:macro-id="8f1c2a4e-3b7d-4c55-9a0e-6d2f1b7c9e10"><ac:rich-text-body><p><strong>Summary</strong></p><ul><li>Jira Issues: 2</li></ul></ac:rich-text-body></ac:structured-macro><h2>Jira Issues</h2><table data-layout="default" ac:local-id="4a3f0c1e-77b2-4e0a-9c1d-2b8e5f6a7d90"><tbody><tr><th>Key</th><th>Summary</th><th>Status</th></tr><tr><td>PROJ-1</td><td>Add audit log export</td><td>In Progress</td></tr><tr><td>PROJ-2</td><td>Rotate signing keys</td><td>To Do</td></tr></tbody></table>`
+
+func TestSameContent(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{
+			name: "identical",
+			a:    generatedBody,
+			b:    generatedBody,
+			want: true,
+		},
+		{
+			name: "only the generation time differs",
+			a:    generatedBody,
+			b:    strings.Replace(generatedBody, "09:00:00", "10:00:00", 1),
+			want: true,
+		},
+		{
+			name: "stored body with macro IDs",
+			a:    storedBody,
+			b:    generatedBody,
+			want: true,
+		},
+		{
+			name: "self-closing tags reformatted",
+			a:    `<p>Chart</p><ac:image><ri:attachment ri:filename="chart.svg" /></ac:image>`,
+			b:    `<p>Chart</p><ac:image><ri:attachment ri:filename="chart.svg"/></ac:image>`,
+			want: true,
+		},
+		{
+			name: "status changed",
+			a:    storedBody,
+			b:    strings.Replace(generatedBody, "In Progress", "Done", 1),
+			want: false,
+		},
+		{
+			name: "macro changed",
+			a:    storedBody,
+			b:    strings.Replace(generatedBody, `ac:name="info"`, `ac:name="note"`, 1),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SameContent(tt.a, tt.b); got != tt.want {
+				t.Errorf("SameContent() = %v, want %v\na: %s\nb: %s", got, tt.want, NormalizeContent(tt.a), NormalizeContent(tt.b))
+			}
+		})
+	}
+}
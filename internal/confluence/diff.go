@@ -0,0 +1,301 @@
+package confluence
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	tablePattern   = regexp.MustCompile(`(?s)<table[^>]*>(.*?)</table>`)
+	headingPattern = regexp.MustCompile(`(?s)<h[1-6][^>]*>(.*?)</h[1-6]>`)
+	rowPattern     = regexp.MustCompile(`(?s)<tr[^>]*>(.*?)</tr>`)
+	cellPattern    = regexp.MustCompile(`(?s)<(th|td)[^>]*>(.*?)</(?:th|td)>`)
+	tagPattern     = regexp.MustCompile(`<[^>]*>`)
+	// blockEnd matches the end of a block element, which contentLines treats as a line
+	blockEnd = regexp.MustCompile(`</(?:p|h[1-6]|li|ul|ol|tr|table|pre|blockquote|ac:rich-text-body|ac:structured-macro)>`)
+)
+
+// TableRow is a row of a table in a page body, identified by the table's
+// heading and the text of the row's first cell, such as an issue key
+type TableRow struct {
+	Table string
+	Key   string
+	// Cells maps column headers to the text of the row's cells
+	Cells map[string]string
+	// columns keeps the order of the cells
+	columns []string
+}
+
+// CellChange is a changed cell of a row found in both bodies
+type CellChange struct {
+	Table  string
+	Key    string
+	Column string
+	Old    string
+	New    string
+}
+
+// ContentDiff is the structural difference between two page bodies
+type ContentDiff struct {
+	Added   []TableRow
+	Removed []TableRow
+	// StatusChanges are changes of the Status or State column
+	StatusChanges []CellChange
+	// CellChanges are changes of all other columns
+	CellChanges []CellChange
+	// Changed is set when the bodies differ at all, outside tables included
+	Changed bool
+}
+
+// DiffContent compares the tables of two storage format bodies row by row
+func DiffContent(oldContent, newContent string) ContentDiff {
+	diff := ContentDiff{Changed: !SameContent(oldContent, newContent)}
+	if !diff.Changed {
+		return diff
+	}
+
+	oldRows, oldOrder := indexRows(parseTableRows(NormalizeContent(oldContent)))
+	newRows, newOrder := indexRows(parseTableRows(NormalizeContent(newContent)))
+
+	for _, id := range oldOrder {
+		if _, ok := newRows[id]; !ok {
+			diff.Removed = append(diff.Removed, oldRows[id])
+		}
+	}
+	for _, id := range newOrder {
+		row := newRows[id]
+		previous, ok := oldRows[id]
+		if !ok {
+			diff.Added = append(diff.Added, row)
+			continue
+		}
+		for _, column := range row.columns {
+			if previous.Cells[column] == row.Cells[column] {
+				continue
+			}
+			change := CellChange{Table: row.Table, Key: row.Key, Column: column, Old: previous.Cells[column], New: row.Cells[column]}
+			if isStatusColumn(column) {
+				diff.StatusChanges = append(diff.StatusChanges, change)
+			} else {
+				diff.CellChanges = append(diff.CellChanges, change)
+			}
+		}
+	}
+	return diff
+}
+
+// TableChanged reports whether any table row was added, removed or changed
+func (d ContentDiff) TableChanged() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || len(d.StatusChanges) > 0 || len(d.CellChanges) > 0
+}
+
+// DescribeChange summarises the difference between two page bodies. Table
+// rows are compared by key, e.g. "3 rows added, 1 status change", and other
+// content as the number of lines (blocks such as paragraphs) added and removed,
+// regardless of their order.
+func DescribeChange(oldContent, newContent string) string {
+	if diff := DiffContent(oldContent, newContent); !diff.Changed || diff.TableChanged() {
+		return diff.Summary()
+	}
+
+	counts := make(map[string]int)
+	for _, line := range contentLines(oldContent) {
+		counts[line]++
+	}
+
+	added := 0
+	for _, line := range contentLines(newContent) {
+		if counts[line] > 0 {
+			counts[line]--
+		} else {
+			added++
+		}
+	}
+	removed := 0
+	for _, n := range counts {
+		removed += n
+	}
+
+	if added == 0 && removed == 0 {
+		return "no content changes"
+	}
+	return fmt.Sprintf("%s added, %d removed", plural(added, "line", "lines"), removed)
+}
+
+// DescribedVersionComment appends a change description to a version comment
+func DescribedVersionComment(base, change string) string {
+	if base == "" {
+		return change
+	}
+	return base + " (" + change + ")"
+}
+
+// contentLines splits content into its normalised blocks, such as paragraphs and
+// list items, so that volatile parts such as the generation timestamp and the
+// line breaks Confluence drops when it stores a page do not count as changes
+func contentLines(content string) []string {
+	var lines []string
+	for _, line := range strings.Split(blockEnd.ReplaceAllString(NormalizeContent(content), "$0\n"), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// Summary describes the diff in a few words, for logs and version comments,
+// e.g. "3 rows added, 1 removed, 2 status changes"
+func (d ContentDiff) Summary() string {
+	if !d.Changed {
+		return "no content changes"
+	}
+
+	var parts []string
+	if n := len(d.Added); n > 0 {
+		parts = append(parts, plural(n, "row", "rows")+" added")
+	}
+	if n := len(d.Removed); n > 0 {
+		if len(parts) == 0 {
+			parts = append(parts, plural(n, "row", "rows")+" removed")
+		} else {
+			parts = append(parts, fmt.Sprintf("%d removed", n))
+		}
+	}
+	if n := len(d.StatusChanges); n > 0 {
+		parts = append(parts, plural(n, "status change", "status changes"))
+	}
+	if n := changedRows(d.CellChanges); n > 0 {
+		parts = append(parts, plural(n, "row", "rows")+" updated")
+	}
+	if len(parts) == 0 {
+		return "changes outside tables"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Write prints the diff as a readable report, one change per line
+func (d ContentDiff) Write(w io.Writer) {
+	fmt.Fprintf(w, "Changes: %s\n", d.Summary())
+	for _, row := range d.Added {
+		fmt.Fprintf(w, "  + [%s] %s\n", row.Table, row.describe())
+	}
+	for _, row := range d.Removed {
+		fmt.Fprintf(w, "  - [%s] %s\n", row.Table, row.describe())
+	}
+	for _, change := range d.StatusChanges {
+		fmt.Fprintf(w, "  ~ [%s] %s: %s -> %s\n", change.Table, change.Key, change.Old, change.New)
+	}
+	for _, change := range d.CellChanges {
+		fmt.Fprintf(w, "  ~ [%s] %s: %s changed\n", change.Table, change.Key, change.Column)
+	}
+}
+
+// describe names a row by its key and its first other column, usually the summary
+func (r TableRow) describe() string {
+	if len(r.columns) > 1 && r.Cells[r.columns[1]] != "" {
+		return r.Key + " " + r.Cells[r.columns[1]]
+	}
+	return r.Key
+}
+
+// parseTableRows returns the data rows of all tables in a body. Columns are named
+// by the table's header row, and tables by the heading before them.
+func parseTableRows(content string) []TableRow {
+	var rows []TableRow
+	for i, loc := range tablePattern.FindAllStringSubmatchIndex(content, -1) {
+		table := fmt.Sprintf("Table %d", i+1)
+		if headings := headingPattern.FindAllStringSubmatch(content[:loc[0]], -1); len(headings) > 0 {
+			table = cellText(headings[len(headings)-1][1])
+		}
+
+		var headers []string
+		for _, rowMatch := range rowPattern.FindAllStringSubmatch(content[loc[2]:loc[3]], -1) {
+			cells := cellPattern.FindAllStringSubmatch(rowMatch[1], -1)
+			if len(cells) == 0 {
+				continue
+			}
+			if isHeaderRow(cells) {
+				headers = headers[:0]
+				for _, cell := range cells {
+					headers = append(headers, cellText(cell[2]))
+				}
+				continue
+			}
+
+			row := TableRow{Table: table, Key: cellText(cells[0][2]), Cells: make(map[string]string)}
+			if row.Key == "" {
+				continue
+			}
+			for j, cell := range cells {
+				column := "Column " + strconv.Itoa(j+1)
+				if j < len(headers) && headers[j] != "" {
+					column = headers[j]
+				}
+				row.Cells[column] = cellText(cell[2])
+				row.columns = append(row.columns, column)
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// indexRows keys rows by table and key, numbering repeated keys within a table
+func indexRows(rows []TableRow) (map[string]TableRow, []string) {
+	index := make(map[string]TableRow, len(rows))
+	order := make([]string, 0, len(rows))
+	for _, row := range rows {
+		id := row.Table + "\x00" + row.Key
+		for n := 2; ; n++ {
+			if _, ok := index[id]; !ok {
+				break
+			}
+			id = row.Table + "\x00" + row.Key + "\x00" + strconv.Itoa(n)
+		}
+		index[id] = row
+		order = append(order, id)
+	}
+	return index, order
+}
+
+// isHeaderRow reports whether all cells of a row are header cells
+func isHeaderRow(cells [][]string) bool {
+	for _, cell := range cells {
+		if cell[1] != "th" {
+			return false
+		}
+	}
+	return true
+}
+
+// isStatusColumn reports whether a column holds the status of an issue or pull request
+func isStatusColumn(column string) bool {
+	column = strings.ToLower(column)
+	return column == "status" || column == "state"
+}
+
+// cellText returns the visible text of a cell's markup
+func cellText(markup string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(tagPattern.ReplaceAllString(markup, " "))), " ")
+}
+
+// changedRows counts the distinct rows among cell changes
+func changedRows(changes []CellChange) int {
+	rows := make(map[string]bool)
+	for _, change := range changes {
+		rows[change.Table+"\x00"+change.Key] = true
+	}
+	return len(rows)
+}
+
+// plural formats a count with the singular or plural form of a noun
+func plural(n int, singular, pluralForm string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+	return fmt.Sprintf("%d %s", n, pluralForm)
+}
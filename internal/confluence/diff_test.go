@@ -0,0 +1,181 @@
+package confluence
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// addedRow is a row for generatedBody's Jira Issues table
+const addedRow = "<tr><td>PROJ-3</td><td>Upgrade Go</td><td>To Do</td></tr>\n"
+
+func TestDiffContent(t *testing.T) {
+	tests := []struct {
+		name          string
+		old, new      string
+		changed       bool
+		added         []string
+		removed       []string
+		statusChanges []CellChange
+		cellChanges   []CellChange
+		summary       string
+	}{
+		{
+			name:    "no changes",
+			old:     storedBody,
+			new:     generatedBody,
+			summary: "no content changes",
+		},
+		{
+			name:    "row added",
+			old:     storedBody,
+			new:     strings.Replace(generatedBody, "</table>", addedRow+"</table>", 1),
+			changed: true,
+			added:   []string{"PROJ-3"},
+			summary: "1 row added",
+		},
+		{
+			name:    "row removed",
+			old:     storedBody,
+			new:     strings.Replace(generatedBody, "<tr><td>PROJ-2</td><td>Rotate signing keys</td><td>To Do</td></tr>\n", "", 1),
+			changed: true,
+			removed: []string{"PROJ-2"},
+			summary: "1 row removed",
+		},
+		{
+			name: "rows added and removed",
+			old:  storedBody,
+			new: strings.Replace(strings.Replace(generatedBody, "</table>", addedRow+"</table>", 1),
+				"<tr><td>PROJ-1</td><td>Add audit log export</td><td>In Progress</td></tr>\n", "", 1),
+			changed: true,
+			added:   []string{"PROJ-3"},
+			removed: []string{"PROJ-1"},
+			summary: "1 row added, 1 removed",
+		},
+		{
+			name:          "status changed",
+			old:           storedBody,
+			new:           strings.Replace(generatedBody, "In Progress", "Done", 1),
+			changed:       true,
+			statusChanges: []CellChange{{Table: "Jira Issues", Key: "PROJ-1", Column: "Status", Old: "In Progress", New: "Done"}},
+			summary:       "1 status change",
+		},
+		{
+			name:        "cell changed",
+			old:         storedBody,
+			new:         strings.Replace(generatedBody, "Rotate signing keys", "Rotate signing keys yearly", 1),
+			changed:     true,
+			cellChanges: []CellChange{{Table: "Jira Issues", Key: "PROJ-2", Column: "Summary", Old: "Rotate signing keys", New: "Rotate signing keys yearly"}},
+			summary:     "1 row updated",
+		},
+		{
+			name:    "change outside tables",
+			old:     storedBody,
+			new:     strings.Replace(generatedBody, "Jira Issues: 2", "Jira Issues: 3", 1),
+			changed: true,
+			summary: "changes outside tables",
+		},
+		{
+			name:          "table without a heading",
+			old:           `<table><tr><th>Key</th><th>State</th></tr><tr><td>#1</td><td>open</td></tr></table>`,
+			new:           `<table><tr><th>Key</th><th>State</th></tr><tr><td>#1</td><td>closed</td></tr></table>`,
+			changed:       true,
+			statusChanges: []CellChange{{Table: "Table 1", Key: "#1", Column: "State", Old: "open", New: "closed"}},
+			summary:       "1 status change",
+		},
+	}
+
+	keys := func(rows []TableRow) []string {
+		var keys []string
+		for _, row := range rows {
+			keys = append(keys, row.Key)
+		}
+		return keys
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := DiffContent(tt.old, tt.new)
+			if diff.Changed != tt.changed {
+				t.Errorf("Changed = %v, want %v", diff.Changed, tt.changed)
+			}
+			if got := keys(diff.Added); !reflect.DeepEqual(got, tt.added) {
+				t.Errorf("Added = %v, want %v", got, tt.added)
+			}
+			if got := keys(diff.Removed); !reflect.DeepEqual(got, tt.removed) {
+				t.Errorf("Removed = %v, want %v", got, tt.removed)
+			}
+			if !reflect.DeepEqual(diff.StatusChanges, tt.statusChanges) {
+				t.Errorf("StatusChanges = %+v, want %+v", diff.StatusChanges, tt.statusChanges)
+			}
+			if !reflect.DeepEqual(diff.CellChanges, tt.cellChanges) {
+				t.Errorf("CellChanges = %+v, want %+v", diff.CellChanges, tt.cellChanges)
+			}
+			if got := diff.Summary(); got != tt.summary {
+				t.Errorf("Summary() = %q, want %q", got, tt.summary)
+			}
+		})
+	}
+}
+
+func TestDescribeChange(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{
+			name: "stored body unchanged",
+			old:  storedBody,
+			new:  generatedBody,
+			want: "no content changes",
+		},
+		{
+			name: "table changes are described by row",
+			old:  storedBody,
+			new:  strings.Replace(strings.Replace(generatedBody, "</table>", addedRow+"</table>", 1), "In Progress", "Done", 1),
+			want: "1 row added, 1 status change",
+		},
+		{
+			name: "paragraph changed on a stored body",
+			old:  storedBody,
+			new:  strings.Replace(generatedBody, "Jira Issues: 2", "Jira Issues: 3", 1),
+			want: "1 line added, 1 removed",
+		},
+		{
+			name: "paragraph added on a stored body",
+			old:  storedBody,
+			new:  generatedBody + "<p>Next review on Friday</p>\n",
+			want: "1 line added, 0 removed",
+		},
+		{
+			name: "paragraphs reordered",
+			old:  "<p>First</p>\n<p>Second</p>",
+			new:  "<p>Second</p><p>First</p><p>Third</p>",
+			want: "1 line added, 0 removed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DescribeChange(tt.old, tt.new); got != tt.want {
+				t.Errorf("DescribeChange() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContentDiffWrite(t *testing.T) {
+	diff := DiffContent(storedBody, strings.Replace(strings.Replace(generatedBody, "</table>", addedRow+"</table>", 1), "In Progress", "Done", 1))
+
+	var out bytes.Buffer
+	diff.Write(&out)
+
+	want := "Changes: 1 row added, 1 status change\n" +
+		"  + [Jira Issues] PROJ-3 Upgrade Go\n" +
+		"  ~ [Jira Issues] PROJ-1: In Progress -> Done\n"
+	if out.String() != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,65 @@
+package confluence
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Errors returned by the client, wrapped with the details of the failure
+var (
+	// ErrNotFound means the page or other content does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrUnauthorized means the credentials were rejected or lack permission
+	ErrUnauthorized = errors.New("not authorized")
+	// ErrNetwork means Confluence could not be reached
+	ErrNetwork = errors.New("network error")
+	// ErrConflict means the page was changed by someone else in the meantime
+	ErrConflict = errors.New("conflict")
+	// ErrManualEdit means the page was last edited by someone other than the
+	// publishing account and manual edits are protected
+	ErrManualEdit = errors.New("page was last edited by someone else")
+	// ErrRegionNotFound means the page has no markers for the generated region
+	ErrRegionNotFound = errors.New("generated region markers not found")
+)
+
+// statusError returns the error for an unsuccessful HTTP response status
+func statusError(statusCode int, status string) error {
+	switch statusCode {
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %s", ErrNotFound, status)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("%w: %s", ErrUnauthorized, status)
+	case http.StatusConflict:
+		return fmt.Errorf("%w: %s", ErrConflict, status)
+	}
+	return fmt.Errorf("unexpected response status: %s", status)
+}
+
+// classifyError wraps an error from the confluence-go-api package, which only
+// reports response statuses in its messages, with the matching client error
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var netErr net.Error
+	var urlErr *url.Error
+	if errors.As(err, &netErr) || errors.As(err, &urlErr) {
+		return fmt.Errorf("%w: %w", ErrNetwork, err)
+	}
+
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "authentication failed"), strings.Contains(msg, "401"), strings.Contains(msg, "403"):
+		return fmt.Errorf("%w: %w", ErrUnauthorized, err)
+	case strings.Contains(msg, "404"):
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case strings.HasPrefix(msg, "conflict"):
+		return fmt.Errorf("%w: %w", ErrConflict, err)
+	}
+	return err
+}
@@ -0,0 +1,96 @@
+package confluence
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// pageIDPattern matches numeric page IDs
+var pageIDPattern = regexp.MustCompile(`^[0-9]+$`)
+
+// pagesPathPattern matches the page ID in Cloud and newer Server page URLs,
+// such as /wiki/spaces/TEAM/pages/123456/Title
+var pagesPathPattern = regexp.MustCompile(`/spaces/([^/]+)/pages/(?:edit-v2/)?([0-9]+)`)
+
+// displayPathPattern matches Server and Data Center page URLs such as /display/TEAM/Page+Title
+var displayPathPattern = regexp.MustCompile(`/display/([^/]+)/([^/?#]+)`)
+
+// PageLocator identifies a page either by ID or by space and title
+type PageLocator struct {
+	ID       string
+	SpaceKey string
+	Title    string
+}
+
+// String describes the page for messages
+func (l PageLocator) String() string {
+	if l.ID != "" {
+		return "page " + l.ID
+	}
+	return fmt.Sprintf("page %q in space %s", l.Title, l.SpaceKey)
+}
+
+// ParsePageLocator parses a page given as a numeric ID, a page URL or a title.
+// Titles are looked up in spaceKey.
+func ParsePageLocator(ref, spaceKey string) (PageLocator, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return PageLocator{}, fmt.Errorf("empty page reference")
+	}
+	if pageIDPattern.MatchString(ref) {
+		return PageLocator{ID: ref}, nil
+	}
+	if !strings.HasPrefix(ref, "http://") && !strings.HasPrefix(ref, "https://") {
+		return PageLocator{SpaceKey: spaceKey, Title: ref}, nil
+	}
+
+	u, err := url.Parse(ref)
+	if err != nil {
+		return PageLocator{}, fmt.Errorf("invalid page URL %q: %w", ref, err)
+	}
+	if id := u.Query().Get("pageId"); pageIDPattern.MatchString(id) {
+		return PageLocator{ID: id}, nil
+	}
+	if m := pagesPathPattern.FindStringSubmatch(u.Path); m != nil {
+		return PageLocator{ID: m[2], SpaceKey: m[1]}, nil
+	}
+	if m := displayPathPattern.FindStringSubmatch(u.EscapedPath()); m != nil {
+		// Display URLs encode spaces in titles as "+"
+		title, err := url.QueryUnescape(m[2])
+		if err != nil {
+			return PageLocator{}, fmt.Errorf("invalid page URL %q: %w", ref, err)
+		}
+		return PageLocator{SpaceKey: m[1], Title: title}, nil
+	}
+	return PageLocator{}, fmt.Errorf("unsupported page URL %q, use a page URL containing the page ID or the page title", ref)
+}
+
+// ResolvePage fetches the page a locator identifies, returning an error wrapping
+// ErrNotFound when it does not exist
+func (c *Client) ResolvePage(loc PageLocator) (*Page, error) {
+	pageID := loc.ID
+	if pageID == "" {
+		var err error
+		pageID, _, err = c.FindPage(loc.SpaceKey, loc.Title)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.GetPage(pageID)
+}
+
+// EnsurePage returns the ID of the page with the given title, creating it with
+// the given content under parentID when it does not exist
+func (c *Client) EnsurePage(spaceKey, title, content, parentID string) (string, error) {
+	pageID, _, err := c.FindPage(spaceKey, title)
+	if err == nil {
+		return pageID, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return "", err
+	}
+	return c.CreatePage(spaceKey, title, content, parentID)
+}
@@ -0,0 +1,72 @@
+package confluence
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// contentProperty is a v1 content property, a JSON value stored with a page
+type contentProperty struct {
+	Key     string           `json:"key"`
+	Value   json.RawMessage  `json:"value"`
+	Version *propertyVersion `json:"version,omitempty"`
+}
+
+// propertyVersion is the version of a content property, increased on each update
+type propertyVersion struct {
+	Number int `json:"number"`
+}
+
+// GetContentProperty decodes the content property key of a page into out and
+// returns an error wrapping ErrNotFound when the page does not have it
+func (c *Client) GetContentProperty(pageID, key string, out interface{}) error {
+	var property contentProperty
+	if err := c.rawRequest(http.MethodGet, propertyPath(pageID, key), nil, &property); err != nil {
+		return fmt.Errorf("failed to get property %q of page %s: %w", key, pageID, err)
+	}
+	if err := json.Unmarshal(property.Value, out); err != nil {
+		return fmt.Errorf("failed to decode property %q of page %s: %w", key, pageID, err)
+	}
+	return nil
+}
+
+// SetContentProperty stores value as JSON in the content property key of a
+// page, creating the property or replacing its value. Other tools can find
+// pages by their properties through CQL once the property is indexed.
+func (c *Client) SetContentProperty(pageID, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal property %q: %w", key, err)
+	}
+
+	var existing contentProperty
+	err = c.rawRequest(http.MethodGet, propertyPath(pageID, key), nil, &existing)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		property := contentProperty{Key: key, Value: data}
+		if err := c.rawRequest(http.MethodPost, "/content/"+pageID+"/property", property, nil); err != nil {
+			return fmt.Errorf("failed to create property %q of page %s: %w", key, pageID, err)
+		}
+	case err != nil:
+		return fmt.Errorf("failed to get property %q of page %s: %w", key, pageID, err)
+	default:
+		property := contentProperty{Key: key, Value: data, Version: &propertyVersion{Number: 1}}
+		if existing.Version != nil {
+			property.Version.Number = existing.Version.Number + 1
+		}
+		if err := c.rawRequest(http.MethodPut, propertyPath(pageID, key), property, nil); err != nil {
+			return fmt.Errorf("failed to update property %q of page %s: %w", key, pageID, err)
+		}
+	}
+
+	c.logger.Debug("Content property set", "pageID", pageID, "key", key)
+	return nil
+}
+
+// propertyPath returns the v1 path of a content property
+func propertyPath(pageID, key string) string {
+	return "/content/" + pageID + "/property/" + url.PathEscape(key)
+}
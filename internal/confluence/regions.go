@@ -0,0 +1,119 @@
+package confluence
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RegionMarkers selects how the generated region of a page is delimited
+type RegionMarkers string
+
+const (
+	// AnchorMarkers delimits the region with anchor macros, which are invisible
+	// on the page and kept by the Confluence editor
+	AnchorMarkers RegionMarkers = "anchor"
+	// CommentMarkers delimits the region with HTML comments, for instances
+	// whose editor keeps comments in the storage format
+	CommentMarkers RegionMarkers = "comment"
+)
+
+// RegionOptions limits an update to the generated region of a page, so that
+// content written by hand around it is kept
+type RegionOptions struct {
+	// Name identifies the region, its markers are "<name>-start" and "<name>-end"
+	Name string
+	// Markers is the style of markers written when the page has none yet
+	Markers RegionMarkers
+	// AppendIfMissing appends the region to the page when the markers are
+	// missing, instead of failing with ErrRegionNotFound
+	AppendIfMissing bool
+}
+
+// regionMarker is the position of a region marker in a page body
+type regionMarker struct {
+	start, end int
+}
+
+// WrapRegion wraps generated content in region markers
+func WrapRegion(name, content string, markers RegionMarkers) string {
+	start, end := regionMarkerText(name, markers)
+	return start + "\n" + content + "\n" + end
+}
+
+// regionMarkerText returns the start and end markers of a region
+func regionMarkerText(name string, markers RegionMarkers) (string, string) {
+	if markers == CommentMarkers {
+		return fmt.Sprintf("<!-- %s-start -->", name), fmt.Sprintf("<!-- %s-end -->", name)
+	}
+	// Anchors are inline macros, the editor keeps them in a paragraph
+	anchor := `<p><ac:structured-macro ac:name="anchor"><ac:parameter ac:name="">%s</ac:parameter></ac:structured-macro></p>`
+	return fmt.Sprintf(anchor, name+"-start"), fmt.Sprintf(anchor, name+"-end")
+}
+
+// markerPatterns match a region marker in either style, including the paragraph
+// around an anchor and the attributes Confluence adds to stored macros
+func markerPatterns(marker string) []*regexp.Regexp {
+	quoted := regexp.QuoteMeta(marker)
+	return []*regexp.Regexp{
+		regexp.MustCompile(`(?:<p[^>]*>\s*)?<ac:structured-macro[^>]*ac:name="anchor"[^>]*>\s*<ac:parameter ac:name="[^"]*">\s*` +
+			quoted + `\s*</ac:parameter>\s*</ac:structured-macro>(?:\s*</p>)?`),
+		regexp.MustCompile(`<!--\s*` + quoted + `\s*-->`),
+	}
+}
+
+// findMarker returns the first occurrence of a marker at or after offset
+func findMarker(body, marker string, offset int) (regionMarker, bool) {
+	var found regionMarker
+	ok := false
+	for _, pattern := range markerPatterns(marker) {
+		loc := pattern.FindStringIndex(body[offset:])
+		if loc == nil {
+			continue
+		}
+		if !ok || offset+loc[0] < found.start {
+			found = regionMarker{start: offset + loc[0], end: offset + loc[1]}
+			ok = true
+		}
+	}
+	return found, ok
+}
+
+// MergeRegion replaces the generated region of body with content, keeping
+// everything outside the region and the markers themselves. When the page has
+// no region, it is appended to the page if AppendIfMissing is set, and an error
+// wrapping ErrRegionNotFound is returned otherwise. Unbalanced or duplicate
+// markers are an error, as it is unclear which content was generated.
+func MergeRegion(body, content string, opts RegionOptions) (string, error) {
+	startMarker, endMarker := opts.Name+"-start", opts.Name+"-end"
+	start, foundStart := findMarker(body, startMarker, 0)
+	end, foundEnd := findMarker(body, endMarker, 0)
+
+	if foundStart {
+		if foundEnd && end.start < start.start {
+			return "", fmt.Errorf("region %q has an end marker before its start marker", opts.Name)
+		}
+		if !foundEnd {
+			return "", fmt.Errorf("region %q has a start marker but no end marker", opts.Name)
+		}
+		if _, again := findMarker(body, startMarker, start.end); again {
+			return "", fmt.Errorf("region %q has more than one start marker", opts.Name)
+		}
+		if _, again := findMarker(body, endMarker, end.end); again {
+			return "", fmt.Errorf("region %q has more than one end marker", opts.Name)
+		}
+		// The markers are kept as stored, Confluence adds attributes to macros
+		return body[:start.end] + "\n" + content + "\n" + body[end.start:], nil
+	}
+	if foundEnd {
+		return "", fmt.Errorf("region %q has an end marker but no start marker", opts.Name)
+	}
+
+	if !opts.AppendIfMissing {
+		return "", fmt.Errorf("region %q: %w", opts.Name, ErrRegionNotFound)
+	}
+	if strings.TrimSpace(body) == "" {
+		return WrapRegion(opts.Name, content, opts.Markers), nil
+	}
+	return body + "\n" + WrapRegion(opts.Name, content, opts.Markers), nil
+}
@@ -0,0 +1,166 @@
+package confluence
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// storedAnchor is an anchor marker as Confluence returns it, with the attributes
+// it adds to stored macros
+func storedAnchor(name string) string {
+	return `<p><ac:structured-macro ac:name="anchor" ac:schema-version="1" ac:macro-id="0b5e9d3a-1c2f-4a7b-8e6d-3f4a5b6c7d8e"><ac:parameter ac:name="">` +
+		name + `</ac:parameter></ac:structured-macro></p>`
+}
+
+func TestMergeRegion(t *testing.T) {
+	anchorStart, anchorEnd := regionMarkerText("report", AnchorMarkers)
+	commentStart, commentEnd := regionMarkerText("report", CommentMarkers)
+
+	tests := []struct {
+		name    string
+		body    string
+		opts    RegionOptions
+		want    string
+		wantErr bool
+		// notFound is set when the error wraps ErrRegionNotFound
+		notFound bool
+	}{
+		{
+			name: "anchor markers",
+			body: "<p>Intro</p>" + anchorStart + "<p>old</p>" + anchorEnd + "<p>Notes</p>",
+			opts: RegionOptions{Name: "report"},
+			want: "<p>Intro</p>" + anchorStart + "\n<p>new</p>\n" + anchorEnd + "<p>Notes</p>",
+		},
+		{
+			name: "stored anchor markers are kept as stored",
+			body: "<p>Intro</p>" + storedAnchor("report-start") + "<p>old</p>" + storedAnchor("report-end"),
+			opts: RegionOptions{Name: "report"},
+			want: "<p>Intro</p>" + storedAnchor("report-start") + "\n<p>new</p>\n" + storedAnchor("report-end"),
+		},
+		{
+			name: "comment markers",
+			body: "<p>Intro</p>\n" + commentStart + "\n<p>old</p>\n" + commentEnd + "\n<p>Notes</p>",
+			opts: RegionOptions{Name: "report", Markers: AnchorMarkers},
+			want: "<p>Intro</p>\n" + commentStart + "\n<p>new</p>\n" + commentEnd + "\n<p>Notes</p>",
+		},
+		{
+			name: "comment markers without spaces",
+			body: "<!--report-start--><p>old</p><!--report-end-->",
+			opts: RegionOptions{Name: "report"},
+			want: "<!--report-start-->\n<p>new</p>\n<!--report-end-->",
+		},
+		{
+			name: "mixed marker styles",
+			body: anchorStart + "<p>old</p>" + commentEnd,
+			opts: RegionOptions{Name: "report"},
+			want: anchorStart + "\n<p>new</p>\n" + commentEnd,
+		},
+		{
+			name: "markers of another region are content",
+			body: commentStart + "<!-- other-start --><p>old</p><!-- other-end -->" + commentEnd,
+			opts: RegionOptions{Name: "report"},
+			want: commentStart + "\n<p>new</p>\n" + commentEnd,
+		},
+		{
+			name:     "missing region fails",
+			body:     "<p>Hand written</p>",
+			opts:     RegionOptions{Name: "report"},
+			wantErr:  true,
+			notFound: true,
+		},
+		{
+			name: "missing region is appended",
+			body: "<p>Hand written</p>",
+			opts: RegionOptions{Name: "report", Markers: AnchorMarkers, AppendIfMissing: true},
+			want: "<p>Hand written</p>\n" + anchorStart + "\n<p>new</p>\n" + anchorEnd,
+		},
+		{
+			name: "missing region is appended with comment markers",
+			body: "<p>Hand written</p>",
+			opts: RegionOptions{Name: "report", Markers: CommentMarkers, AppendIfMissing: true},
+			want: "<p>Hand written</p>\n" + commentStart + "\n<p>new</p>\n" + commentEnd,
+		},
+		{
+			name: "empty page gets only the region",
+			body: "  \n",
+			opts: RegionOptions{Name: "report", Markers: CommentMarkers, AppendIfMissing: true},
+			want: commentStart + "\n<p>new</p>\n" + commentEnd,
+		},
+		{
+			name:    "start marker without end marker",
+			body:    commentStart + "<p>old</p>",
+			opts:    RegionOptions{Name: "report", AppendIfMissing: true},
+			wantErr: true,
+		},
+		{
+			name:    "end marker without start marker",
+			body:    "<p>old</p>" + commentEnd,
+			opts:    RegionOptions{Name: "report", AppendIfMissing: true},
+			wantErr: true,
+		},
+		{
+			name:    "end marker before start marker",
+			body:    commentEnd + "<p>old</p>" + commentStart,
+			opts:    RegionOptions{Name: "report"},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate region",
+			body:    commentStart + "<p>a</p>" + commentEnd + commentStart + "<p>b</p>" + commentEnd,
+			opts:    RegionOptions{Name: "report"},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate start marker",
+			body:    commentStart + "<p>a</p>" + anchorStart + "<p>b</p>" + commentEnd,
+			opts:    RegionOptions{Name: "report"},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate end marker",
+			body:    commentStart + "<p>a</p>" + commentEnd + "<p>b</p>" + anchorEnd,
+			opts:    RegionOptions{Name: "report"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergeRegion(tt.body, "<p>new</p>", tt.opts)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("MergeRegion() = %q, want an error", got)
+				}
+				if errors.Is(err, ErrRegionNotFound) != tt.notFound {
+					t.Errorf("MergeRegion() error = %v, wraps ErrRegionNotFound = %v, want %v", err, !tt.notFound, tt.notFound)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MergeRegion() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("MergeRegion() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrapRegionRoundTrip(t *testing.T) {
+	for _, markers := range []RegionMarkers{AnchorMarkers, CommentMarkers} {
+		t.Run(string(markers), func(t *testing.T) {
+			body := "<p>Intro</p>\n" + WrapRegion("report", "<p>old</p>", markers) + "\n<p>Notes</p>"
+			got, err := MergeRegion(body, "<p>new</p>", RegionOptions{Name: "report"})
+			if err != nil {
+				t.Fatalf("MergeRegion() error = %v", err)
+			}
+			if strings.Contains(got, "old") || !strings.Contains(got, "<p>new</p>") {
+				t.Errorf("MergeRegion() did not replace the region: %s", got)
+			}
+			if !strings.HasPrefix(got, "<p>Intro</p>\n") || !strings.HasSuffix(got, "\n<p>Notes</p>") {
+				t.Errorf("MergeRegion() did not keep the content around the region: %s", got)
+			}
+		})
+	}
+}
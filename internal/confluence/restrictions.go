@@ -0,0 +1,123 @@
+package confluence
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// EditRestrictions lists who may edit a page. Users are account IDs on Confluence
+// Cloud and user names on Server and Data Center.
+type EditRestrictions struct {
+	Groups []string
+	Users  []string
+}
+
+// restrictionRequest is the v1 body restricting one operation on a page
+type restrictionRequest struct {
+	Operation    string              `json:"operation"`
+	Restrictions restrictionSubjects `json:"restrictions"`
+}
+
+// restrictionSubjects are the users and groups an operation is restricted to
+type restrictionSubjects struct {
+	User  []map[string]string `json:"user"`
+	Group []map[string]string `json:"group"`
+}
+
+// currentRestrictions is the v1 response listing the restrictions of a page per operation
+type currentRestrictions struct {
+	Results []struct {
+		Operation    string `json:"operation"`
+		Restrictions struct {
+			User struct {
+				Results []struct {
+					AccountID string `json:"accountId"`
+					Username  string `json:"username"`
+					UserKey   string `json:"userKey"`
+				} `json:"results"`
+			} `json:"user"`
+			Group struct {
+				Results []struct {
+					ID   string `json:"id"`
+					Name string `json:"name"`
+				} `json:"results"`
+			} `json:"group"`
+		} `json:"restrictions"`
+	} `json:"results"`
+}
+
+// RestrictEditing limits editing a page to the given groups and users,
+// replacing any edit restrictions the page had. The publishing account is
+// always included so later runs can still update the page. Confluence replaces
+// all restrictions of a page at once, so the view restrictions the page has are
+// read first and sent back unchanged.
+func (c *Client) RestrictEditing(pageID string, restrictions EditRestrictions) error {
+	self, err := c.currentUser()
+	if err != nil {
+		return err
+	}
+
+	// Cloud identifies users by account ID, Server and Data Center by name
+	userField := "username"
+	selfEntry := map[string]string{"type": "known", "username": self.Username}
+	if self.AccountID != "" {
+		userField = "accountId"
+		selfEntry = map[string]string{"type": "known", "accountId": self.AccountID}
+	}
+
+	subjects := restrictionSubjects{
+		User:  []map[string]string{selfEntry},
+		Group: []map[string]string{},
+	}
+	for _, user := range restrictions.Users {
+		if user == "" || user == self.AccountID || user == self.Username {
+			continue
+		}
+		subjects.User = append(subjects.User, map[string]string{"type": "known", userField: user})
+	}
+	for _, group := range restrictions.Groups {
+		if group != "" {
+			subjects.Group = append(subjects.Group, map[string]string{"type": "group", "name": group})
+		}
+	}
+
+	path := "/content/" + url.PathEscape(pageID) + "/restriction"
+	var existing currentRestrictions
+	if err := c.rawRequest(http.MethodGet, path+"?expand=restrictions.user,restrictions.group", nil, &existing); err != nil {
+		return fmt.Errorf("failed to get restrictions of page %s: %w", pageID, err)
+	}
+
+	body := []restrictionRequest{{Operation: "update", Restrictions: subjects}}
+	for _, operation := range existing.Results {
+		if operation.Operation != "read" {
+			continue
+		}
+		read := restrictionSubjects{User: []map[string]string{}, Group: []map[string]string{}}
+		for _, user := range operation.Restrictions.User.Results {
+			switch {
+			case user.AccountID != "":
+				read.User = append(read.User, map[string]string{"type": "known", "accountId": user.AccountID})
+			case user.UserKey != "":
+				read.User = append(read.User, map[string]string{"type": "known", "userKey": user.UserKey})
+			default:
+				read.User = append(read.User, map[string]string{"type": "known", "username": user.Username})
+			}
+		}
+		for _, group := range operation.Restrictions.Group.Results {
+			if group.ID != "" {
+				read.Group = append(read.Group, map[string]string{"type": "group", "id": group.ID})
+			} else {
+				read.Group = append(read.Group, map[string]string{"type": "group", "name": group.Name})
+			}
+		}
+		body = append(body, restrictionRequest{Operation: "read", Restrictions: read})
+	}
+
+	if err := c.rawRequest(http.MethodPut, path, body, nil); err != nil {
+		return fmt.Errorf("failed to restrict editing of page %s: %w", pageID, err)
+	}
+
+	c.logger.Info("Page edit restrictions set", "pageID", pageID, "groups", restrictions.Groups, "users", restrictions.Users)
+	return nil
+}
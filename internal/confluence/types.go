@@ -1,18 +1,23 @@
 package confluence
 
-// Page represents a Confluence page
+import "time"
+
+// Page represents a Confluence page, as returned by both the v1 and v2 REST APIs
 type Page struct {
-	ID      string  `json:"id,omitempty"`
-	Type    string  `json:"type"`
-	Title   string  `json:"title"`
-	Space   Space   `json:"space"`
-	Body    Body    `json:"body"`
-	Version Version `json:"version,omitempty"`
+	ID       string  `json:"id,omitempty"`
+	Type     string  `json:"type"`
+	Title    string  `json:"title"`
+	Space    Space   `json:"space"`
+	ParentID string  `json:"parentId,omitempty"`
+	Body     Body    `json:"body"`
+	Version  Version `json:"version,omitempty"`
 }
 
 // Space represents a Confluence space
 type Space struct {
 	Key string `json:"key"`
+	// ID is the numeric space ID used by the v2 API
+	ID string `json:"id,omitempty"`
 }
 
 // Body represents the content body of a Confluence page
@@ -28,9 +33,14 @@ type Storage struct {
 
 // Version represents the version information of a Confluence page
 type Version struct {
-	Number    int    `json:"number"`
-	Message   string `json:"message,omitempty"`
-	MinorEdit bool   `json:"minorEdit"`
+	Number    int       `json:"number"`
+	Message   string    `json:"message,omitempty"`
+	MinorEdit bool      `json:"minorEdit"`
+	When      time.Time `json:"when,omitempty"`
+	// AuthorID identifies the editor: the account ID on Cloud, the user key on Server
+	AuthorID string `json:"authorId,omitempty"`
+	// Author is the editor's display name, where the API returns it
+	Author string `json:"author,omitempty"`
 }
 
 // SearchResult represents the result of a Confluence content search
@@ -58,3 +68,19 @@ type SearchResult struct {
 	Limit int `json:"limit"`
 	Start int `json:"start"`
 }
+
+// UpdateOptions controls how a page update is recorded in the page history
+type UpdateOptions struct {
+	// VersionComment is shown in the page history
+	VersionComment string
+	// MinorEdit records the update without notifying watchers
+	MinorEdit bool
+	// ProtectManualEdits refuses to overwrite a page last edited by anyone
+	// other than the publishing account
+	ProtectManualEdits bool
+	// Region, when set, limits the update to the generated region of the page
+	Region *RegionOptions
+	// DescribeChanges appends a summary of the changes to the version comment,
+	// see DescribeChange
+	DescribeChanges bool
+}
@@ -0,0 +1,159 @@
+package correlation
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/krzko/jiragitfluence/pkg/models"
+)
+
+// jiraKeyPattern matches Jira issue keys such as ABC-123
+var jiraKeyPattern = regexp.MustCompile(`\b[A-Z][A-Z0-9_]+-[1-9][0-9]*\b`)
+
+// branchKeyPattern matches Jira issue keys in branch names, which are often lower case (abc-123-fix-login)
+var branchKeyPattern = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])([a-z][a-z0-9_]+-[1-9][0-9]*)`)
+
+// ExtractKeys returns the Jira-style keys referenced in the given texts, in order of appearance
+func ExtractKeys(texts ...string) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, text := range texts {
+		for _, key := range jiraKeyPattern.FindAllString(text, -1) {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// ExtractBranchKeys returns the Jira-style keys referenced in a branch name, upper-cased
+func ExtractBranchKeys(branch string) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, match := range branchKeyPattern.FindAllStringSubmatch(branch, -1) {
+		key := strings.ToUpper(match[1])
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Link connects GitHub issues and pull requests to the Jira issues they reference.
+// References found at fetch time are kept only when they belong to a fetched Jira
+// project: one of Metadata.JiraProjects, or when the issues were fetched by JQL
+// alone, the project of a fetched issue. Links are recorded in both directions,
+// once per pull request or issue even when the data holds it twice. Existing links are replaced,
+// so Link can be run again after data from several files has been combined.
+// It returns the number of links created.
+func Link(data *models.AggregatedData) int {
+	// Index Jira issues and the projects they belong to
+	issueIndex := make(map[string]int, len(data.JiraIssues))
+	projects := make(map[string]bool)
+	for _, project := range data.Metadata.JiraProjects {
+		projects[strings.ToUpper(project)] = true
+	}
+	fetchedByJQL := len(projects) == 0
+	for i := range data.JiraIssues {
+		data.JiraIssues[i].LinkedPRs = nil
+		data.JiraIssues[i].LinkedGitHubIssues = nil
+		issueIndex[data.JiraIssues[i].Key] = i
+		if fetchedByJQL {
+			projects[projectOf(data.JiraIssues[i].Key)] = true
+		}
+	}
+	linked := make(map[string]bool)
+
+	links := 0
+
+	for i := range data.GitHubPRs {
+		pr := &data.GitHubPRs[i]
+		pr.LinkedJiraKeys = matchKeys(pr.JiraKeyRefs, projects)
+		for _, key := range pr.LinkedJiraKeys {
+			if idx, ok := issueIndex[key]; ok && !isLinked(linked, key, "pr", pr.Repository, pr.Number) {
+				data.JiraIssues[idx].LinkedPRs = append(data.JiraIssues[idx].LinkedPRs, models.GitHubRef{
+					Repository: pr.Repository,
+					Number:     pr.Number,
+					Title:      pr.Title,
+					URL:        pr.URL,
+					State:      pr.State,
+					Merged:     pr.MergeStatus == "merged",
+					IsDraft:    pr.IsDraft,
+				})
+				links++
+			}
+		}
+	}
+
+	for i := range data.GitHubIssues {
+		issue := &data.GitHubIssues[i]
+		issue.LinkedJiraKeys = matchKeys(issue.JiraKeyRefs, projects)
+		for _, key := range issue.LinkedJiraKeys {
+			if idx, ok := issueIndex[key]; ok && !isLinked(linked, key, "issue", issue.Repository, issue.Number) {
+				data.JiraIssues[idx].LinkedGitHubIssues = append(data.JiraIssues[idx].LinkedGitHubIssues, models.GitHubRef{
+					Repository: issue.Repository,
+					Number:     issue.Number,
+					Title:      issue.Title,
+					URL:        issue.URL,
+					State:      issue.State,
+				})
+				links++
+			}
+		}
+	}
+
+	// Keep links in a stable order regardless of fetch order
+	for i := range data.JiraIssues {
+		sortRefs(data.JiraIssues[i].LinkedPRs)
+		sortRefs(data.JiraIssues[i].LinkedGitHubIssues)
+	}
+
+	return links
+}
+
+// matchKeys returns the distinct references that belong to one of the given projects
+func matchKeys(refs []string, projects map[string]bool) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		if projects[projectOf(ref)] && !seen[ref] {
+			seen[ref] = true
+			keys = append(keys, ref)
+		}
+	}
+	return keys
+}
+
+// isLinked reports whether a Jira issue is already linked to a GitHub pull request
+// or issue, and records the link otherwise
+func isLinked(linked map[string]bool, key, kind, repository string, number int) bool {
+	id := fmt.Sprintf("%s\x00%s\x00%s#%d", key, kind, repository, number)
+	if linked[id] {
+		return true
+	}
+	linked[id] = true
+	return false
+}
+
+// projectOf returns the project part of an issue key
+func projectOf(key string) string {
+	if i := strings.LastIndex(key, "-"); i > 0 {
+		return key[:i]
+	}
+	return key
+}
+
+// sortRefs sorts GitHub references by repository and number
+func sortRefs(refs []models.GitHubRef) {
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Repository != refs[j].Repository {
+			return refs[i].Repository < refs[j].Repository
+		}
+		return refs[i].Number < refs[j].Number
+	})
+}
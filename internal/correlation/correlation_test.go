@@ -0,0 +1,153 @@
+package correlation
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/krzko/jiragitfluence/pkg/models"
+)
+
+func TestExtractKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		texts []string
+		want  []string
+	}{
+		{"title and body", []string{"ABC-123: fix login", "Also see XYZ_2-7"}, []string{"ABC-123", "XYZ_2-7"}},
+		{"duplicates", []string{"ABC-1 and ABC-1", "ABC-1"}, []string{"ABC-1"}},
+		{"key-like strings", []string{"Encode as UTF-8 per ISO-9001"}, []string{"UTF-8", "ISO-9001"}},
+		{"not keys", []string{"abc-1, A-1, ABC-0, ABC-012, xABC-1"}, nil},
+		{"punctuation around keys", []string{"(ABC-1), [ABC-2]: ABC-3."}, []string{"ABC-1", "ABC-2", "ABC-3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractKeys(tt.texts...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractBranchKeys(t *testing.T) {
+	tests := []struct {
+		branch string
+		want   []string
+	}{
+		{"abc-123-fix-login", []string{"ABC-123"}},
+		{"feature/ABC-123_fix", []string{"ABC-123"}},
+		{"abc-1-and-abc-1", []string{"ABC-1"}},
+		{"release-2", []string{"RELEASE-2"}},
+		{"main", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			if got := ExtractBranchKeys(tt.branch); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractBranchKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLink(t *testing.T) {
+	jiraIssues := []models.JiraIssue{{Key: "ABC-1"}, {Key: "ABC-2"}, {Key: "UTF-8"}}
+	pr := func(number int, refs ...string) models.GitHubPR {
+		return models.GitHubPR{Repository: "o/r", Number: number, JiraKeyRefs: refs}
+	}
+
+	tests := []struct {
+		name     string
+		projects []string
+		prs      []models.GitHubPR
+		issues   []models.GitHubIssue
+		// wantPRs and wantIssues map Jira keys to the numbers of their linked items
+		wantPRs    map[string][]int
+		wantIssues map[string][]int
+		wantLinks  int
+	}{
+		{
+			name:       "keys of fetched projects",
+			projects:   []string{"ABC"},
+			prs:        []models.GitHubPR{pr(1, "ABC-1"), pr(2, "ABC-2", "ABC-1")},
+			issues:     []models.GitHubIssue{{Repository: "o/r", Number: 3, JiraKeyRefs: []string{"ABC-2"}}},
+			wantPRs:    map[string][]int{"ABC-1": {1, 2}, "ABC-2": {2}},
+			wantIssues: map[string][]int{"ABC-2": {3}},
+			wantLinks:  4,
+		},
+		{
+			name:      "key-like strings outside the fetched projects",
+			projects:  []string{"abc"},
+			prs:       []models.GitHubPR{pr(1, "UTF-8", "ISO-9001", "ABC-1")},
+			wantPRs:   map[string][]int{"ABC-1": {1}},
+			wantLinks: 1,
+		},
+		{
+			name:      "keys of an unfetched project",
+			projects:  []string{"ABC"},
+			prs:       []models.GitHubPR{pr(1, "XYZ-1"), pr(2, "ABC-99")},
+			wantLinks: 0,
+		},
+		{
+			name:      "projects of fetched issues without projects",
+			prs:       []models.GitHubPR{pr(1, "UTF-8", "ABC-2", "XYZ-1")},
+			wantPRs:   map[string][]int{"ABC-2": {1}, "UTF-8": {1}},
+			wantLinks: 2,
+		},
+		{
+			name:      "duplicate references",
+			projects:  []string{"ABC"},
+			prs:       []models.GitHubPR{pr(1, "ABC-1", "ABC-1")},
+			wantPRs:   map[string][]int{"ABC-1": {1}},
+			wantLinks: 1,
+		},
+		{
+			name:      "duplicate pull requests",
+			projects:  []string{"ABC"},
+			prs:       []models.GitHubPR{pr(1, "ABC-1"), pr(1, "ABC-1")},
+			wantPRs:   map[string][]int{"ABC-1": {1}},
+			wantLinks: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &models.AggregatedData{
+				JiraIssues:   append([]models.JiraIssue(nil), jiraIssues...),
+				GitHubPRs:    tt.prs,
+				GitHubIssues: tt.issues,
+				Metadata:     models.Metadata{JiraProjects: tt.projects},
+			}
+
+			if got := Link(data); got != tt.wantLinks {
+				t.Errorf("Link() = %d, want %d", got, tt.wantLinks)
+			}
+			// Linking again replaces the links
+			if got := Link(data); got != tt.wantLinks {
+				t.Errorf("second Link() = %d, want %d", got, tt.wantLinks)
+			}
+
+			gotPRs, gotIssues := make(map[string][]int), make(map[string][]int)
+			for _, issue := range data.JiraIssues {
+				for _, ref := range issue.LinkedPRs {
+					gotPRs[issue.Key] = append(gotPRs[issue.Key], ref.Number)
+				}
+				for _, ref := range issue.LinkedGitHubIssues {
+					gotIssues[issue.Key] = append(gotIssues[issue.Key], ref.Number)
+				}
+			}
+			if tt.wantPRs == nil {
+				tt.wantPRs = map[string][]int{}
+			}
+			if tt.wantIssues == nil {
+				tt.wantIssues = map[string][]int{}
+			}
+			if !reflect.DeepEqual(gotPRs, tt.wantPRs) {
+				t.Errorf("linked pull requests = %v, want %v", gotPRs, tt.wantPRs)
+			}
+			if !reflect.DeepEqual(gotIssues, tt.wantIssues) {
+				t.Errorf("linked issues = %v, want %v", gotIssues, tt.wantIssues)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package generator
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/krzko/jiragitfluence/pkg/models"
+)
+
+// imageExtensions are attachment extensions shown inline as images
+var imageExtensions = map[string]bool{
+	".png":  true,
+	".svg":  true,
+	".jpg":  true,
+	".jpeg": true,
+	".gif":  true,
+}
+
+// addAttachments shows image attachments inline and links the other attachments.
+// The files are expected to be attached to the page by publish --attach.
+func (g *Generator) addAttachments(content *strings.Builder, attachments []string) {
+	var images, files []string
+	for _, attachment := range attachments {
+		filename := filepath.Base(attachment)
+		if imageExtensions[strings.ToLower(filepath.Ext(filename))] {
+			images = append(images, filename)
+		} else {
+			files = append(files, filename)
+		}
+	}
+
+	for _, filename := range images {
+		content.WriteString(fmt.Sprintf("<p><ac:image ac:alt=\"%s\"><ri:attachment ri:filename=\"%s\" /></ac:image></p>\n", escapeHTML(filename), escapeHTML(filename)))
+	}
+
+	if len(files) > 0 {
+		content.WriteString("<h2>Attachments</h2>\n")
+		content.WriteString("<ul>\n")
+		for _, filename := range files {
+			content.WriteString(fmt.Sprintf("<li><ac:link><ri:attachment ri:filename=\"%s\" /><ac:plain-text-link-body><![CDATA[%s]]></ac:plain-text-link-body></ac:link></li>\n", escapeHTML(filename), filename))
+		}
+		content.WriteString("</ul>\n\n")
+	}
+}
+
+// csvHeader is the header row of the CSV export
+var csvHeader = []string{"Source", "Key", "Type", "Title", "Status", "Assignee", "Labels", "Linked", "Created", "Updated", "URL"}
+
+// WriteCSV writes the Jira issues, GitHub issues and pull requests as one CSV table
+func WriteCSV(w io.Writer, data *models.AggregatedData) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, issue := range data.JiraIssues {
+		var linked []string
+		for _, ref := range issue.LinkedPRs {
+			linked = append(linked, ref.Repository+"#"+strconv.Itoa(ref.Number))
+		}
+		for _, ref := range issue.LinkedGitHubIssues {
+			linked = append(linked, ref.Repository+"#"+strconv.Itoa(ref.Number))
+		}
+		if err := writer.Write([]string{
+			"Jira", issue.Key, issue.IssueType, issue.Summary, issue.Status, issue.Assignee,
+			strings.Join(issue.Labels, ", "), strings.Join(linked, ", "),
+			csvTime(issue.CreatedDate), csvTime(issue.UpdatedDate), issue.URL,
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, issue := range data.GitHubIssues {
+		if err := writer.Write([]string{
+			"GitHub", issue.Repository + "#" + strconv.Itoa(issue.Number), "Issue", issue.Title, issue.State,
+			strings.Join(issue.Assignees, ", "), strings.Join(issue.Labels, ", "), strings.Join(issue.LinkedJiraKeys, ", "),
+			csvTime(issue.CreatedDate), csvTime(issue.UpdatedDate), issue.URL,
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, pr := range data.GitHubPRs {
+		state := pr.State
+		if pr.MergeStatus == "merged" {
+			state = "merged"
+		}
+		if err := writer.Write([]string{
+			"GitHub", pr.Repository + "#" + strconv.Itoa(pr.Number), "Pull Request", pr.Title, state, pr.Author,
+			strings.Join(pr.Labels, ", "), strings.Join(pr.LinkedJiraKeys, ", "),
+			csvTime(pr.CreatedDate), csvTime(pr.UpdatedDate), pr.URL,
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvTime formats a time for the CSV export, leaving unset times empty
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/krzko/jiragitfluence/pkg/models"
+)
+
+// Status chart layout, in pixels
+const (
+	chartWidth       = 640
+	chartLabelWidth  = 220
+	chartBarMaxWidth = 340
+	chartRowHeight   = 26
+	chartBarHeight   = 18
+	chartTitleHeight = 36
+)
+
+// chartBar is one bar of the status chart
+type chartBar struct {
+	label  string
+	status string
+	count  int
+}
+
+// WriteStatusChart renders the number of Jira issues per status and GitHub issues
+// and pull requests per state as an SVG bar chart, for attaching to the page
+func WriteStatusChart(w io.Writer, data *models.AggregatedData) error {
+	var bars []chartBar
+	bars = append(bars, countBars("Jira", jiraStatuses(data))...)
+	bars = append(bars, countBars("GitHub issues", githubIssueStates(data))...)
+	bars = append(bars, countBars("Pull requests", pullRequestStates(data))...)
+
+	maxCount := 1
+	for _, bar := range bars {
+		if bar.count > maxCount {
+			maxCount = bar.count
+		}
+	}
+
+	height := chartTitleHeight + len(bars)*chartRowHeight + 10
+	var svg strings.Builder
+	svg.WriteString(fmt.Sprintf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\" font-family=\"Arial, sans-serif\" font-size=\"12\">\n", chartWidth, height, chartWidth, height))
+	svg.WriteString(fmt.Sprintf("<rect width=\"%d\" height=\"%d\" fill=\"#FFFFFF\"/>\n", chartWidth, height))
+	svg.WriteString("<text x=\"10\" y=\"22\" font-size=\"15\" font-weight=\"bold\" fill=\"#172B4D\">Items by status</text>\n")
+	if len(bars) == 0 {
+		svg.WriteString(fmt.Sprintf("<text x=\"10\" y=\"%d\" fill=\"#6B778C\">No items</text>\n", chartTitleHeight+14))
+	}
+
+	for i, bar := range bars {
+		y := chartTitleHeight + i*chartRowHeight
+		width := bar.count * chartBarMaxWidth / maxCount
+		if width < 2 {
+			width = 2
+		}
+		_, fill := statusColors(bar.status)
+		svg.WriteString(fmt.Sprintf("<text x=\"%d\" y=\"%d\" text-anchor=\"end\" fill=\"#172B4D\">%s</text>\n", chartLabelWidth-8, y+chartBarHeight-5, html.EscapeString(bar.label)))
+		svg.WriteString(fmt.Sprintf("<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" rx=\"3\" fill=\"%s\"/>\n", chartLabelWidth, y, width, chartBarHeight, fill))
+		svg.WriteString(fmt.Sprintf("<text x=\"%d\" y=\"%d\" fill=\"#172B4D\">%d</text>\n", chartLabelWidth+width+6, y+chartBarHeight-5, bar.count))
+	}
+	svg.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, svg.String())
+	return err
+}
+
+// countBars turns counts per status into bars, largest first
+func countBars(source string, counts map[string]int) []chartBar {
+	bars := make([]chartBar, 0, len(counts))
+	for status, count := range counts {
+		bars = append(bars, chartBar{label: source + ": " + status, status: status, count: count})
+	}
+	sort.Slice(bars, func(i, j int) bool {
+		if bars[i].count != bars[j].count {
+			return bars[i].count > bars[j].count
+		}
+		return bars[i].label < bars[j].label
+	})
+	return bars
+}
+
+// jiraStatuses counts Jira issues per status
+func jiraStatuses(data *models.AggregatedData) map[string]int {
+	counts := make(map[string]int)
+	for _, issue := range data.JiraIssues {
+		counts[issue.Status]++
+	}
+	return counts
+}
+
+// githubIssueStates counts GitHub issues per state
+func githubIssueStates(data *models.AggregatedData) map[string]int {
+	counts := make(map[string]int)
+	for _, issue := range data.GitHubIssues {
+		counts[issue.State]++
+	}
+	return counts
+}
+
+// pullRequestStates counts pull requests per state, telling merged from closed
+func pullRequestStates(data *models.AggregatedData) map[string]int {
+	counts := make(map[string]int)
+	for _, pr := range data.GitHubPRs {
+		state := pr.State
+		if pr.MergeStatus == "merged" {
+			state = "merged"
+		}
+		counts[state]++
+	}
+	return counts
+}
@@ -69,6 +69,9 @@ type Options struct {
 	RoadmapGrouping     string      // How to group items in roadmap (e.g., "epic", "theme", "team")
 	RoadmapView         RoadmapView // Type of roadmap view
 	IncludeDependencies bool        // Whether to show dependencies between roadmap items
+
+	// Attachments are filenames of page attachments to show or link on the page
+	Attachments []string
 }
 
 // NewGenerator creates a new generator
@@ -103,6 +106,11 @@ func (g *Generator) Generate(data *models.AggregatedData, opts Options) (string,
 		return "", fmt.Errorf("unsupported format: %s", opts.Format)
 	}
 
+	// Reference the files attached to the page
+	if len(opts.Attachments) > 0 {
+		g.addAttachments(&content, opts.Attachments)
+	}
+
 	// Add footer with metadata if requested
 	if opts.IncludeMetadata {
 		g.addFooter(&content, data)
@@ -121,6 +129,27 @@ func (g *Generator) addHeader(content *strings.Builder, data *models.AggregatedD
 
 	content.WriteString(fmt.Sprintf("<p><strong>Generated:</strong> %s</p>\n\n", time.Now().Format(time.RFC1123)))
 
+	// Warn readers when the Jira data was truncated during the fetch
+	if data.Metadata.JiraTruncated {
+		content.WriteString("<ac:structured-macro ac:name=\"warning\">\n")
+		content.WriteString("<ac:rich-text-body>\n")
+		content.WriteString(fmt.Sprintf("<p><strong>Incomplete data:</strong> only %d of %d matching Jira issues were fetched.</p>\n", len(data.JiraIssues), data.Metadata.JiraTotal))
+		content.WriteString("</ac:rich-text-body>\n")
+		content.WriteString("</ac:structured-macro>\n\n")
+	}
+
+	// Warn readers when the GitHub fetch was cut short by rate limiting
+	if data.Metadata.GitHubIncomplete {
+		content.WriteString("<ac:structured-macro ac:name=\"warning\">\n")
+		content.WriteString("<ac:rich-text-body>\n")
+		content.WriteString("<p><strong>Incomplete data:</strong> the GitHub fetch stopped early because of API rate limits.</p>\n")
+		if len(data.Metadata.GitHubPartialRepos) > 0 {
+			content.WriteString(fmt.Sprintf("<p>Partially fetched repositories: %s</p>\n", html.EscapeString(strings.Join(data.Metadata.GitHubPartialRepos, ", "))))
+		}
+		content.WriteString("</ac:rich-text-body>\n")
+		content.WriteString("</ac:structured-macro>\n\n")
+	}
+
 	// Add summary in an info panel
 	content.WriteString("<ac:structured-macro ac:name=\"info\">\n")
 	content.WriteString("<ac:rich-text-body>\n")
@@ -182,6 +211,7 @@ func (g *Generator) generateTableFormat(content *strings.Builder, data *models.A
 		content.WriteString("<th style=\"background-color: #f4f5f7; text-align: center; border: 1px solid #c1c7d0;\">Assignee</th>\n")
 		content.WriteString("<th style=\"background-color: #f4f5f7; text-align: center; border: 1px solid #c1c7d0;\">Priority</th>\n")
 		content.WriteString("<th style=\"background-color: #f4f5f7; text-align: center; border: 1px solid #c1c7d0;\">Updated</th>\n")
+		content.WriteString("<th style=\"background-color: #f4f5f7; text-align: center; border: 1px solid #c1c7d0;\">Pull Requests</th>\n")
 		content.WriteString("</tr>\n")
 
 		// Data rows
@@ -216,6 +246,10 @@ func (g *Generator) generateTableFormat(content *strings.Builder, data *models.A
 			content.WriteString(fmt.Sprintf("<td style=\"border: 1px solid #c1c7d0;%s\">\n", rowStyle))
 			content.WriteString(fmt.Sprintf("%s\n", issue.UpdatedDate.Format("2006-01-02")))
 			content.WriteString("</td>\n")
+
+			content.WriteString(fmt.Sprintf("<td style=\"border: 1px solid #c1c7d0;%s\">\n", rowStyle))
+			content.WriteString(fmt.Sprintf("%s\n", formatLinkedPRs(issue.LinkedPRs)))
+			content.WriteString("</td>\n")
 			content.WriteString("</tr>\n")
 		}
 
@@ -258,6 +292,9 @@ func (g *Generator) generateTableFormat(content *strings.Builder, data *models.A
 			
 			content.WriteString(fmt.Sprintf("<td style=\"border: 1px solid #c1c7d0;%s\">\n", rowStyle))
 			content.WriteString(fmt.Sprintf("%s\n", escapeHTML(issue.Title)))
+			if match := formatContentMatch(issue.ContentMatch); match != "" {
+				content.WriteString(fmt.Sprintf("<br/>%s\n", match))
+			}
 			content.WriteString("</td>\n")
 			
 			// Style the state similar to Jira status
@@ -293,6 +330,7 @@ func (g *Generator) generateTableFormat(content *strings.Builder, data *models.A
 		content.WriteString("<th style=\"background-color: #f4f5f7; text-align: center; border: 1px solid #c1c7d0;\">Title</th>\n")
 		content.WriteString("<th style=\"background-color: #f4f5f7; text-align: center; border: 1px solid #c1c7d0;\">State</th>\n")
 		content.WriteString("<th style=\"background-color: #f4f5f7; text-align: center; border: 1px solid #c1c7d0;\">Assignees</th>\n")
+		content.WriteString("<th style=\"background-color: #f4f5f7; text-align: center; border: 1px solid #c1c7d0;\">Review / CI</th>\n")
 		content.WriteString("<th style=\"background-color: #f4f5f7; text-align: center; border: 1px solid #c1c7d0;\">Updated</th>\n")
 		content.WriteString("</tr>\n")
 
@@ -315,6 +353,9 @@ func (g *Generator) generateTableFormat(content *strings.Builder, data *models.A
 			
 			content.WriteString(fmt.Sprintf("<td style=\"border: 1px solid #c1c7d0;%s\">\n", rowStyle))
 			content.WriteString(fmt.Sprintf("%s\n", escapeHTML(pr.Title)))
+			if match := formatContentMatch(pr.ContentMatch); match != "" {
+				content.WriteString(fmt.Sprintf("<br/>%s\n", match))
+			}
 			content.WriteString("</td>\n")
 			
 			// Add draft status if applicable and style the state
@@ -333,6 +374,10 @@ func (g *Generator) generateTableFormat(content *strings.Builder, data *models.A
 			content.WriteString(fmt.Sprintf("%s\n", escapeHTML(strings.Join(pr.Assignees, ", "))))
 			content.WriteString("</td>\n")
 			
+			content.WriteString(fmt.Sprintf("<td style=\"border: 1px solid #c1c7d0;%s\">\n", rowStyle))
+			content.WriteString(fmt.Sprintf("%s\n", escapeHTML(prReviewSummary(pr))))
+			content.WriteString("</td>\n")
+			
 			content.WriteString(fmt.Sprintf("<td style=\"border: 1px solid #c1c7d0;%s\">\n", rowStyle))
 			content.WriteString(fmt.Sprintf("%s\n", pr.UpdatedDate.Format("2006-01-02")))
 			content.WriteString("</td>\n")
@@ -391,6 +436,9 @@ func (g *Generator) generateKanbanFormat(content *strings.Builder, data *models.
 				if issue.Assignee != "" {
 					content.WriteString(fmt.Sprintf("<p><em>Assignee: %s</em></p>\n", escapeHTML(issue.Assignee)))
 				}
+				if prs := formatLinkedPRs(issue.LinkedPRs); prs != "" {
+					content.WriteString(fmt.Sprintf("<p>PRs: %s</p>\n", prs))
+				}
 				content.WriteString("</ac:rich-text-body>\n")
 				content.WriteString("</ac:structured-macro>\n")
 			}
@@ -420,6 +468,9 @@ func (g *Generator) generateKanbanFormat(content *strings.Builder, data *models.
 				content.WriteString("<ac:rich-text-body>\n")
 				content.WriteString(fmt.Sprintf("<p><strong><a href=\"%s\">%s #%d</a></strong></p>\n", issue.URL, escapeHTML(issue.Repository), issue.Number))
 				content.WriteString(fmt.Sprintf("<p>%s</p>\n", escapeHTML(issue.Title)))
+				if match := formatContentMatch(issue.ContentMatch); match != "" {
+					content.WriteString(fmt.Sprintf("<p>%s</p>\n", match))
+				}
 				if len(issue.Assignees) > 0 {
 					content.WriteString(fmt.Sprintf("<p><em>Assignee: %s</em></p>\n", escapeHTML(strings.Join(issue.Assignees, ", "))))
 				}
@@ -452,12 +503,18 @@ func (g *Generator) generateKanbanFormat(content *strings.Builder, data *models.
 				content.WriteString("<ac:rich-text-body>\n")
 				content.WriteString(fmt.Sprintf("<p><strong><a href=\"%s\">%s #%d</a></strong></p>\n", pr.URL, escapeHTML(pr.Repository), pr.Number))
 				content.WriteString(fmt.Sprintf("<p>%s</p>\n", escapeHTML(pr.Title)))
+				if match := formatContentMatch(pr.ContentMatch); match != "" {
+					content.WriteString(fmt.Sprintf("<p>%s</p>\n", match))
+				}
 				if len(pr.Assignees) > 0 {
 					content.WriteString(fmt.Sprintf("<p><em>Assignee: %s</em></p>\n", escapeHTML(strings.Join(pr.Assignees, ", "))))
 				}
 				if pr.IsDraft {
 					content.WriteString("<p><em>Draft</em></p>\n")
 				}
+				if summary := prReviewSummary(pr); summary != "" {
+					content.WriteString(fmt.Sprintf("<p><em>%s</em></p>\n", escapeHTML(summary)))
+				}
 				content.WriteString("</ac:rich-text-body>\n")
 				content.WriteString("</ac:structured-macro>\n")
 			}
@@ -552,6 +609,14 @@ func mapGitHubPRStateToColumn(state, mergeStatus string) string {
 
 // getStatusStyle returns a styled status indicator for Confluence Storage Format
 func getStatusStyle(status string) string {
+	color, backgroundColor := statusColors(status)
+
+	// Use Confluence's native span with styling
+	return fmt.Sprintf("<span style=\"display:inline-block; padding:2px 5px; background-color:%s; color:%s; border-radius:3px; font-size:11px; text-align:center;\">%s</span>", backgroundColor, color, html.EscapeString(status))
+}
+
+// statusColors returns the text and background colours used for a status
+func statusColors(status string) (string, string) {
 	var color string
 	var backgroundColor string
 	
@@ -570,8 +635,56 @@ func getStatusStyle(status string) string {
 		backgroundColor = "#6554C0" // Purple background for other statuses
 	}
 	
-	// Use Confluence's native span with styling
-	return fmt.Sprintf("<span style=\"display:inline-block; padding:2px 5px; background-color:%s; color:%s; border-radius:3px; font-size:11px; text-align:center;\">%s</span>", backgroundColor, color, html.EscapeString(status))
+	return color, backgroundColor
+}
+
+// prReviewSummary summarises the review decision and CI status of an enriched
+// pull request, e.g. "approved, CI red". It is empty when neither is known.
+func prReviewSummary(pr models.GitHubPR) string {
+	var parts []string
+	if pr.ReviewDecision != "" {
+		parts = append(parts, strings.ReplaceAll(pr.ReviewDecision, "_", " "))
+	}
+	switch pr.CIStatus {
+	case "":
+	case "success":
+		parts = append(parts, "CI green")
+	case "failure", "error":
+		parts = append(parts, "CI red")
+	default:
+		parts = append(parts, "CI "+pr.CIStatus)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatContentMatch renders where the content filter matched an item.
+// Title matches are left out, as the title is already shown.
+func formatContentMatch(match *models.ContentMatch) string {
+	if match == nil || match.Source == "title" {
+		return ""
+	}
+	return fmt.Sprintf("<em>Matched in %s: &quot;%s&quot;</em>", escapeHTML(match.Source), escapeHTML(match.Snippet))
+}
+
+// formatLinkedPRs renders the open and merged pull requests linked to a Jira issue.
+// Closed pull requests that were never merged are left out.
+func formatLinkedPRs(refs []models.GitHubRef) string {
+	var links []string
+	for _, ref := range refs {
+		var status string
+		switch {
+		case ref.Merged:
+			status = "merged"
+		case ref.State == "open" && ref.IsDraft:
+			status = "draft"
+		case ref.State == "open":
+			status = "open"
+		default:
+			continue
+		}
+		links = append(links, fmt.Sprintf("<a href=\"%s\">%s#%d</a> (%s)", ref.URL, escapeHTML(ref.Repository), ref.Number, status))
+	}
+	return strings.Join(links, ", ")
 }
 
 // escapeHTML safely escapes HTML content for Confluence
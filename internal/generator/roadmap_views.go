@@ -710,14 +710,72 @@ func (g *Generator) addDependenciesVisualization(content *strings.Builder, data
 	content.WriteString("skinparam defaultFontName Arial\n")
 	content.WriteString("skinparam defaultFontSize 12\n")
 
-	// Add nodes for Jira issues
+	// Collect summaries for node labels, linked issues outside the data set are shown by key
+	summaries := make(map[string]string)
 	for _, issue := range data.JiraIssues {
-		if issue.Dependencies != nil && len(issue.Dependencies) > 0 {
-			content.WriteString(fmt.Sprintf("rectangle \"%s\\n%s\" as %s\n",
-				issue.Key, escapeHTML(issue.Summary), issue.Key))
+		summaries[issue.Key] = issue.Summary
+	}
+
+	// Build edges in "from acts on to" direction, so "A is blocked by B" and
+	// "B blocks A" collapse into a single B --> A edge
+	type edge struct {
+		from, to, label string
+		blocking        bool
+	}
+	var edges []edge
+	seenEdges := make(map[string]bool)
+	nodes := make(map[string]bool)
+	var nodeOrder []string
+	addNode := func(key string) {
+		if !nodes[key] {
+			nodes[key] = true
+			nodeOrder = append(nodeOrder, key)
 		}
 	}
 
+	for _, issue := range data.JiraIssues {
+		for _, dep := range issue.Dependencies {
+			from, to := dep.Key, issue.Key
+			label := dep.Relation
+			switch {
+			case dep.Direction == models.DependencyOutward, dep.Type == "":
+				// Outward links and plain dependencies point from this issue
+				from, to = issue.Key, dep.Key
+			default:
+				// Use the outward wording for inward links, e.g. "blocks" instead of "is blocked by".
+				// Data fetched before the wording was stored falls back to the link type name
+				label = dep.Outward
+				if label == "" {
+					label = strings.ToLower(dep.Type)
+				}
+			}
+
+			id := from + "|" + to + "|" + strings.ToLower(dep.Type)
+			if seenEdges[id] {
+				continue
+			}
+			seenEdges[id] = true
+
+			addNode(from)
+			addNode(to)
+			edges = append(edges, edge{
+				from:     from,
+				to:       to,
+				label:    label,
+				blocking: strings.EqualFold(dep.Type, "Blocks") || dep.Type == "",
+			})
+		}
+	}
+
+	// Add nodes for Jira issues
+	for _, key := range nodeOrder {
+		label := key
+		if summary, ok := summaries[key]; ok {
+			label = fmt.Sprintf("%s\\n%s", key, escapeHTML(summary))
+		}
+		content.WriteString(fmt.Sprintf("rectangle \"%s\" as %s\n", label, plantUMLID(key)))
+	}
+
 	// Add nodes for GitHub issues
 	for i, issue := range data.GitHubIssues {
 		if issue.Dependencies != nil && len(issue.Dependencies) > 0 {
@@ -729,10 +787,16 @@ func (g *Generator) addDependenciesVisualization(content *strings.Builder, data
 
 	// We're not including PRs in the roadmap as per requirements
 
-	// Add dependency relationships
-	for _, issue := range data.JiraIssues {
-		for _, dep := range issue.Dependencies {
-			content.WriteString(fmt.Sprintf("%s --> %s\n", issue.Key, dep))
+	// Add dependency relationships, blocking links are solid and other link types dashed
+	for _, e := range edges {
+		arrow := "-->"
+		if !e.blocking {
+			arrow = "..>"
+		}
+		if e.label != "" {
+			content.WriteString(fmt.Sprintf("%s %s %s : %s\n", plantUMLID(e.from), arrow, plantUMLID(e.to), e.label))
+		} else {
+			content.WriteString(fmt.Sprintf("%s %s %s\n", plantUMLID(e.from), arrow, plantUMLID(e.to)))
 		}
 	}
 
@@ -740,3 +804,8 @@ func (g *Generator) addDependenciesVisualization(content *strings.Builder, data
 	content.WriteString("]]></ac:plain-text-body>\n")
 	content.WriteString("</ac:structured-macro>\n")
 }
+
+// plantUMLID converts an issue key into a valid PlantUML identifier
+func plantUMLID(key string) string {
+	return strings.NewReplacer("-", "_", " ", "_", "/", "_", "#", "_").Replace(key)
+}
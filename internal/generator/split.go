@@ -0,0 +1,153 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/krzko/jiragitfluence/pkg/models"
+)
+
+// SplitBy defines how aggregated data is split into separate pages
+type SplitBy string
+
+const (
+	// SplitByTeam splits Jira issues by team
+	SplitByTeam SplitBy = "team"
+	// SplitByEpic splits Jira issues by epic
+	SplitByEpic SplitBy = "epic"
+	// SplitByRepo splits GitHub items by repository
+	SplitByRepo SplitBy = "repo"
+	// SplitByProject splits Jira issues by project key
+	SplitByProject SplitBy = "project"
+)
+
+// Split is the part of the aggregated data belonging to one split value
+type Split struct {
+	Value string
+	Data  *models.AggregatedData
+}
+
+// SplitData splits aggregated data by team, epic, repository or Jira project.
+// For Jira based splits, GitHub items linked to the issues of a split follow
+// them; for repository splits, the Jira issues linked from a repository follow
+// it. Items without a value are left out, and splits are sorted by value.
+func SplitData(data *models.AggregatedData, by SplitBy) ([]Split, error) {
+	groups := make(map[string]*models.AggregatedData)
+	group := func(value string) *models.AggregatedData {
+		g, ok := groups[value]
+		if !ok {
+			g = &models.AggregatedData{
+				JiraIssues:   []models.JiraIssue{},
+				GitHubIssues: []models.GitHubIssue{},
+				GitHubPRs:    []models.GitHubPR{},
+				Metadata:     data.Metadata,
+			}
+			groups[value] = g
+		}
+		return g
+	}
+
+	switch by {
+	case SplitByTeam, SplitByEpic, SplitByProject:
+		keyGroup := make(map[string]string)
+		for _, issue := range data.JiraIssues {
+			value := jiraSplitValue(issue, by)
+			if value == "" {
+				continue
+			}
+			g := group(value)
+			g.JiraIssues = append(g.JiraIssues, issue)
+			keyGroup[issue.Key] = value
+		}
+		for _, issue := range data.GitHubIssues {
+			for _, value := range linkedGroups(issue.LinkedJiraKeys, keyGroup) {
+				g := group(value)
+				g.GitHubIssues = append(g.GitHubIssues, issue)
+			}
+		}
+		for _, pr := range data.GitHubPRs {
+			for _, value := range linkedGroups(pr.LinkedJiraKeys, keyGroup) {
+				g := group(value)
+				g.GitHubPRs = append(g.GitHubPRs, pr)
+			}
+		}
+	case SplitByRepo:
+		keyRepos := make(map[string][]string)
+		for _, issue := range data.GitHubIssues {
+			if issue.Repository == "" {
+				continue
+			}
+			g := group(issue.Repository)
+			g.GitHubIssues = append(g.GitHubIssues, issue)
+			for _, key := range issue.LinkedJiraKeys {
+				keyRepos[key] = appendUnique(keyRepos[key], issue.Repository)
+			}
+		}
+		for _, pr := range data.GitHubPRs {
+			if pr.Repository == "" {
+				continue
+			}
+			g := group(pr.Repository)
+			g.GitHubPRs = append(g.GitHubPRs, pr)
+			for _, key := range pr.LinkedJiraKeys {
+				keyRepos[key] = appendUnique(keyRepos[key], pr.Repository)
+			}
+		}
+		for _, issue := range data.JiraIssues {
+			for _, repo := range keyRepos[issue.Key] {
+				g := group(repo)
+				g.JiraIssues = append(g.JiraIssues, issue)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported split: %s (supported: team, epic, repo, project)", by)
+	}
+
+	splits := make([]Split, 0, len(groups))
+	for value, g := range groups {
+		splits = append(splits, Split{Value: value, Data: g})
+	}
+	sort.Slice(splits, func(i, j int) bool { return splits[i].Value < splits[j].Value })
+	return splits, nil
+}
+
+// jiraSplitValue returns the split value of a Jira issue
+func jiraSplitValue(issue models.JiraIssue, by SplitBy) string {
+	switch by {
+	case SplitByTeam:
+		return issue.Team
+	case SplitByEpic:
+		// Epics are their own group, so the epic page shows the epic itself
+		if strings.EqualFold(issue.IssueType, "Epic") {
+			return issue.Key
+		}
+		return issue.EpicLink
+	case SplitByProject:
+		if idx := strings.LastIndex(issue.Key, "-"); idx > 0 {
+			return issue.Key[:idx]
+		}
+	}
+	return ""
+}
+
+// linkedGroups returns the distinct groups of the given Jira keys
+func linkedGroups(keys []string, keyGroup map[string]string) []string {
+	var values []string
+	for _, key := range keys {
+		if value, ok := keyGroup[key]; ok {
+			values = appendUnique(values, value)
+		}
+	}
+	return values
+}
+
+// appendUnique appends value unless it is already present
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
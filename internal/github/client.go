@@ -2,20 +2,88 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/go-github/v60/github"
 	"github.com/krzko/jiragitfluence/internal/config"
+	"github.com/krzko/jiragitfluence/internal/correlation"
 	"github.com/krzko/jiragitfluence/pkg/models"
 	"golang.org/x/oauth2"
 )
 
 // Client handles interactions with the GitHub API
 type Client struct {
-	client *github.Client
-	logger *slog.Logger
+	client         *github.Client
+	backend        string
+	concurrency    int
+	failFast       bool
+	enrichPRs      bool
+	searchComments bool
+	commentSem     chan struct{}
+	comments       *commentCache
+	matchAnyLabel  bool
+	projectFields  config.GitHubProjectFields
+	labelFields    config.GitHubLabelFields
+	formFields     config.GitHubFormFields
+	limiter        *rateLimiter
+	logger         *slog.Logger
+}
+
+// RepoError records a repository that could not be fetched
+type RepoError struct {
+	Repo string
+	Err  error
+}
+
+// FetchErrors is returned alongside partial results when some repositories
+// failed and fail-fast is disabled, or when the rate limit wait budget ran out
+type FetchErrors []RepoError
+
+// Error implements the error interface
+func (e FetchErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, repoErr := range e {
+		msgs = append(msgs, fmt.Sprintf("%s: %v", repoErr.Repo, repoErr.Err))
+	}
+	return fmt.Sprintf("failed to fetch %d repositories: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// Repos returns the repositories that failed, without those fetched partially
+func (e FetchErrors) Repos() []string {
+	var repos []string
+	for _, repoErr := range e {
+		if !errors.Is(repoErr.Err, ErrWaitBudgetExceeded) {
+			repos = append(repos, repoErr.Repo)
+		}
+	}
+	return repos
+}
+
+// PartialRepos returns the repositories cut short by the rate limit wait budget,
+// whose data was fetched partially
+func (e FetchErrors) PartialRepos() []string {
+	var repos []string
+	for _, repoErr := range e {
+		if errors.Is(repoErr.Err, ErrWaitBudgetExceeded) {
+			repos = append(repos, repoErr.Repo)
+		}
+	}
+	return repos
+}
+
+// RateLimited reports whether any repository was cut short by the rate limit wait budget
+func (e FetchErrors) RateLimited() bool {
+	for _, repoErr := range e {
+		if errors.Is(repoErr.Err, ErrWaitBudgetExceeded) {
+			return true
+		}
+	}
+	return false
 }
 
 // NewClient creates a new GitHub client
@@ -26,48 +94,196 @@ func NewClient(cfg config.GitHubConfig, logger *slog.Logger) *Client {
 	tc := oauth2.NewClient(context.Background(), ts)
 	client := github.NewClient(tc)
 
+	backend := strings.ToLower(cfg.Backend)
+	if backend == "" {
+		backend = BackendREST
+	}
+
+	commentConcurrency := cfg.CommentConcurrency
+	if commentConcurrency < 1 {
+		commentConcurrency = 1
+	}
+
 	return &Client{
-		client: client,
-		logger: logger,
+		client:         client,
+		backend:        backend,
+		concurrency:    cfg.Concurrency,
+		failFast:       cfg.FailFast,
+		enrichPRs:      cfg.EnrichPRs,
+		searchComments: cfg.SearchComments,
+		commentSem:     make(chan struct{}, commentConcurrency),
+		comments:       newCommentCache(cfg.CommentCache, logger),
+		matchAnyLabel:  strings.EqualFold(cfg.LabelMatch, config.LabelMatchAny),
+		projectFields:  cfg.ProjectFields,
+		labelFields:    cfg.LabelFields,
+		formFields:     cfg.FormFields,
+		limiter:        newRateLimiter(cfg.MaxWait, logger),
+		logger:         logger,
 	}
 }
 
-// FetchIssuesAndPRs fetches issues and pull requests from GitHub
-func (c *Client) FetchIssuesAndPRs(repos []string, labels []string, contentFilter string, creator string) ([]models.GitHubIssue, []models.GitHubPR, error) {
-	var issues []models.GitHubIssue
-	var prs []models.GitHubPR
+// DateFilter restricts fetched issues and pull requests by date. Zero times are ignored.
+type DateFilter struct {
+	// UpdatedAfter drops items last updated before this time
+	UpdatedAfter time.Time
+	// ClosedAfter drops items closed before this time, open items are kept
+	ClosedAfter time.Time
+}
+
+// updatedBefore reports whether an item updated at the given time falls before the update cutoff
+func (d DateFilter) updatedBefore(updated time.Time) bool {
+	return !d.UpdatedAfter.IsZero() && updated.Before(d.UpdatedAfter)
+}
 
+// closedBefore reports whether an item closed at the given time falls before the close cutoff
+func (d DateFilter) closedBefore(closed *time.Time) bool {
+	return !d.ClosedAfter.IsZero() && closed != nil && closed.Before(d.ClosedAfter)
+}
+
+// searchQualifiers returns the search qualifiers that apply the update cutoff server-side
+func (d DateFilter) searchQualifiers() string {
+	if d.UpdatedAfter.IsZero() {
+		return ""
+	}
+	return " updated:>=" + d.UpdatedAfter.UTC().Format(time.RFC3339)
+}
+
+// repoResult holds the data fetched for a single repository
+type repoResult struct {
+	issues []models.GitHubIssue
+	prs    []models.GitHubPR
+	err    error
+}
+
+// FetchIssuesAndPRs fetches issues and pull requests from GitHub.
+// Repositories are fetched concurrently, results are returned in the order of repos.
+// With fail-fast disabled, repositories that fail are skipped and reported
+// through a FetchErrors error alongside the results of the others.
+func (c *Client) FetchIssuesAndPRs(repos []string, labels []string, contentFilter string, creator string, dates DateFilter) ([]models.GitHubIssue, []models.GitHubPR, error) {
+	type repoRef struct {
+		owner, repo string
+	}
+
+	// Validate all repository paths before fetching anything
+	refs := make([]repoRef, 0, len(repos))
 	for _, repoPath := range repos {
-		// Parse owner and repo from the repo path
 		parts := strings.Split(repoPath, "/")
 		if len(parts) != 2 {
 			return nil, nil, fmt.Errorf("invalid repository path: %s, expected format: owner/repo", repoPath)
 		}
-		owner, repo := parts[0], parts[1]
+		refs = append(refs, repoRef{owner: parts[0], repo: parts[1]})
+	}
 
-		// Fetch issues
-		repoIssues, err := c.fetchIssues(owner, repo, labels, contentFilter, creator)
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to fetch issues for %s/%s: %w", owner, repo, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	workers := c.concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(refs) {
+		workers = len(refs)
+	}
+
+	c.logger.Info("Fetching GitHub repositories", "repos", len(refs), "backend", c.backend, "concurrency", workers, "fail_fast", c.failFast)
+
+	results := make([]repoResult, len(refs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = c.fetchRepo(ctx, refs[i].owner, refs[i].repo, labels, contentFilter, creator, dates)
+				if results[i].err != nil && c.failFast && !errors.Is(results[i].err, ErrWaitBudgetExceeded) {
+					cancel()
+				}
+			}
+		}()
+	}
+
+	for i := range refs {
+		if ctx.Err() != nil {
+			break
 		}
-		issues = append(issues, repoIssues...)
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
 
-		// Fetch pull requests
-		repoPRs, err := c.fetchPullRequests(owner, repo, labels, contentFilter, creator)
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to fetch pull requests for %s/%s: %w", owner, repo, err)
+	var issues []models.GitHubIssue
+	var prs []models.GitHubPR
+	var fetchErrs FetchErrors
+
+	for i, result := range results {
+		if result.err != nil {
+			repoPath := fmt.Sprintf("%s/%s", refs[i].owner, refs[i].repo)
+
+			// Keep whatever was fetched before the rate limit wait budget ran out
+			if errors.Is(result.err, ErrWaitBudgetExceeded) {
+				c.logger.Warn("GitHub repository fetched partially", "repo", repoPath, "issues", len(result.issues), "prs", len(result.prs))
+				issues = append(issues, result.issues...)
+				prs = append(prs, result.prs...)
+				fetchErrs = append(fetchErrs, RepoError{Repo: repoPath, Err: result.err})
+				continue
+			}
+
+			if c.failFast {
+				// Report the failure that triggered cancellation rather than the ones it caused
+				if errors.Is(result.err, context.Canceled) {
+					continue
+				}
+				return nil, nil, result.err
+			}
+			c.logger.Error("Failed to fetch GitHub repository, skipping", "repo", repoPath, "error", result.err)
+			fetchErrs = append(fetchErrs, RepoError{Repo: repoPath, Err: result.err})
+			continue
 		}
-		prs = append(prs, repoPRs...)
+		issues = append(issues, result.issues...)
+		prs = append(prs, result.prs...)
 	}
 
-	c.logger.Info("Fetched GitHub data", "issues", len(issues), "prs", len(prs))
+	c.logger.Info("Fetched GitHub data", "issues", len(issues), "prs", len(prs), "failed_repos", len(fetchErrs))
+	if len(fetchErrs) > 0 {
+		return issues, prs, fetchErrs
+	}
 	return issues, prs, nil
 }
 
+// fetchRepo fetches issues and pull requests for a single repository.
+// When the rate limit wait budget runs out, the items fetched so far are returned with the error.
+func (c *Client) fetchRepo(ctx context.Context, owner, repo string, labels []string, contentFilter string, creator string, dates DateFilter) repoResult {
+	fetchIssues, fetchPullRequests := c.fetchIssues, c.fetchPullRequests
+	if c.backend == BackendGraphQL {
+		fetchIssues, fetchPullRequests = c.fetchIssuesGraphQL, c.fetchPullRequestsGraphQL
+	}
+
+	// Fetch issues
+	issues, err := fetchIssues(ctx, owner, repo, labels, contentFilter, creator, dates)
+	if err != nil {
+		return repoResult{issues: issues, err: fmt.Errorf("failed to fetch issues for %s/%s: %w", owner, repo, err)}
+	}
+
+	// Fetch pull requests
+	prs, err := fetchPullRequests(ctx, owner, repo, labels, contentFilter, creator, dates)
+	if err != nil {
+		return repoResult{issues: issues, prs: prs, err: fmt.Errorf("failed to fetch pull requests for %s/%s: %w", owner, repo, err)}
+	}
+
+	// Search the comments of items whose title and body did not match
+	issues, prs, err = c.filterByComments(ctx, contentFilter, issues, prs)
+	if err != nil {
+		return repoResult{issues: issues, prs: prs, err: fmt.Errorf("failed to search comments for %s/%s: %w", owner, repo, err)}
+	}
+
+	return repoResult{issues: issues, prs: prs}
+}
+
 // fetchIssues fetches issues from a GitHub repository
-func (c *Client) fetchIssues(owner, repo string, labels []string, contentFilter string, creator string) ([]models.GitHubIssue, error) {
+func (c *Client) fetchIssues(ctx context.Context, owner, repo string, labels []string, contentFilter string, creator string, dates DateFilter) ([]models.GitHubIssue, error) {
 	var allIssues []models.GitHubIssue
-	ctx := context.Background()
 
 	opts := &github.IssueListByRepoOptions{
 		State:     "all",
@@ -78,7 +294,9 @@ func (c *Client) fetchIssues(owner, repo string, labels []string, contentFilter
 		},
 	}
 
-	if len(labels) > 0 {
+	// The Issues API requires all of the labels, so matching any of several is done here
+	filterLabels := c.matchAnyLabel && len(labels) > 1
+	if len(labels) > 0 && !filterLabels {
 		opts.Labels = labels
 	}
 
@@ -87,10 +305,21 @@ func (c *Client) fetchIssues(owner, repo string, labels []string, contentFilter
 		opts.Creator = creator
 	}
 
+	// Only list issues updated since the cutoff
+	if !dates.UpdatedAfter.IsZero() {
+		opts.Since = dates.UpdatedAfter
+	}
+
 	for {
-		issues, resp, err := c.client.Issues.ListByRepo(ctx, owner, repo, opts)
+		var issues []*github.Issue
+		var resp *github.Response
+		err := c.limiter.call(ctx, func() (*github.Response, error) {
+			var err error
+			issues, resp, err = c.client.Issues.ListByRepo(ctx, owner, repo, opts)
+			return resp, err
+		})
 		if err != nil {
-			return nil, err
+			return allIssues, err
 		}
 
 		for _, issue := range issues {
@@ -99,14 +328,26 @@ func (c *Client) fetchIssues(owner, repo string, labels []string, contentFilter
 				continue
 			}
 
-			// Apply content filter if specified
+			if filterLabels && !c.hasLabels(labelNames(issue.Labels), labels) {
+				continue
+			}
+
+			if dates.closedBefore(issue.ClosedAt.GetTime()) {
+				continue
+			}
+
+			// Apply content filter if specified, leaving items for the comment search when enabled
+			var match *models.ContentMatch
 			if contentFilter != "" {
-				if !containsContent(issue, contentFilter) {
+				match = matchContent(contentFilter, issue.GetTitle(), issue.GetBody())
+				if match == nil && !c.searchComments {
 					continue
 				}
 			}
 
 			ghIssue := convertGitHubIssue(issue, owner, repo)
+			c.applyRoadmapFields(&ghIssue, issue.GetBody())
+			ghIssue.ContentMatch = match
 			allIssues = append(allIssues, ghIssue)
 		}
 
@@ -121,9 +362,20 @@ func (c *Client) fetchIssues(owner, repo string, labels []string, contentFilter
 }
 
 // fetchPullRequests fetches pull requests from a GitHub repository
-func (c *Client) fetchPullRequests(owner, repo string, labels []string, contentFilter string, creator string) ([]models.GitHubPR, error) {
+func (c *Client) fetchPullRequests(ctx context.Context, owner, repo string, labels []string, contentFilter string, creator string, dates DateFilter) ([]models.GitHubPR, error) {
+	// The pull request list has no label or creator filter, so filtered pull
+	// requests are found through the Search API instead of listing them all.
+	// Searches matching more than the Search API returns list and filter instead.
+	if len(labels) > 0 || creator != "" {
+		prs, err := c.searchPullRequests(ctx, owner, repo, labels, contentFilter, creator, dates)
+		if !errors.Is(err, errSearchResultLimit) {
+			return prs, err
+		}
+		c.logger.Info("Pull request search exceeds the Search API result limit, listing pull requests instead",
+			"repo", fmt.Sprintf("%s/%s", owner, repo), "limit", searchResultLimit)
+	}
+
 	var allPRs []models.GitHubPR
-	ctx := context.Background()
 
 	opts := &github.PullRequestListOptions{
 		State:     "all",
@@ -134,38 +386,53 @@ func (c *Client) fetchPullRequests(owner, repo string, labels []string, contentF
 		},
 	}
 
-	// Note: The GitHub API doesn't support filtering PRs by creator directly in the list options
-	// We'll filter them manually after fetching
-
-	for {
-		prs, resp, err := c.client.PullRequests.List(ctx, owner, repo, opts)
+	reachedCutoff := false
+	for !reachedCutoff {
+		var prs []*github.PullRequest
+		var resp *github.Response
+		err := c.limiter.call(ctx, func() (*github.Response, error) {
+			var err error
+			prs, resp, err = c.client.PullRequests.List(ctx, owner, repo, opts)
+			return resp, err
+		})
 		if err != nil {
-			return nil, err
+			return allPRs, err
 		}
 
 		for _, pr := range prs {
-			// Filter by labels if specified
-			if len(labels) > 0 && !hasMatchingLabels(pr.Labels, labels) {
+			// Pull requests are sorted by update time, so the rest are older than the cutoff
+			if dates.updatedBefore(pr.GetUpdatedAt().Time) {
+				reachedCutoff = true
+				break
+			}
+
+			if dates.closedBefore(pr.ClosedAt.GetTime()) {
 				continue
 			}
 
-			// Apply content filter if specified
+			// Apply label and creator filters if specified
+			if !c.hasLabels(labelNames(pr.Labels), labels) {
+				continue
+			}
+			if creator != "" && pr.GetUser().GetLogin() != creator {
+				continue
+			}
+
+			// Apply content filter if specified, leaving items for the comment search when enabled
+			var match *models.ContentMatch
 			if contentFilter != "" {
-				if !containsPRContent(pr, contentFilter) {
+				match = matchContent(contentFilter, pr.GetTitle(), pr.GetBody())
+				if match == nil && !c.searchComments {
 					continue
 				}
 			}
 
-			// Apply creator filter if specified
-			if creator != "" && pr.User != nil && pr.User.GetLogin() != creator {
-				continue
-			}
-
 			ghPR := convertGitHubPR(pr, owner, repo)
+			ghPR.ContentMatch = match
 			allPRs = append(allPRs, ghPR)
 		}
 
-		if resp.NextPage == 0 {
+		if reachedCutoff || resp.NextPage == 0 {
 			break
 		}
 		opts.Page = resp.NextPage
@@ -175,15 +442,18 @@ func (c *Client) fetchPullRequests(owner, repo string, labels []string, contentF
 	return allPRs, nil
 }
 
-// hasMatchingLabels checks if any of the PR labels match the filter labels
-func hasMatchingLabels(prLabels []*github.Label, filterLabels []string) bool {
+// hasLabels checks an item's labels against the filter labels, requiring any or all
+// of them depending on the configured label matching
+func (c *Client) hasLabels(labels []string, filterLabels []string) bool {
 	if len(filterLabels) == 0 {
 		return true
 	}
-
-	for _, prLabel := range prLabels {
+	if !c.matchAnyLabel {
+		return hasAllLabels(labels, filterLabels)
+	}
+	for _, label := range labels {
 		for _, filterLabel := range filterLabels {
-			if prLabel.GetName() == filterLabel {
+			if label == filterLabel {
 				return true
 			}
 		}
@@ -204,53 +474,31 @@ func convertGitHubIssue(issue *github.Issue, owner, repo string) models.GitHubIs
 	}
 
 	return models.GitHubIssue{
-		Title:       issue.GetTitle(),
-		Number:      issue.GetNumber(),
-		State:       issue.GetState(),
-		Labels:      labels,
-		Assignees:   assignees,
-		CreatedDate: issue.GetCreatedAt().Time,
-		UpdatedDate: issue.GetUpdatedAt().Time,
-		URL:         issue.GetHTMLURL(),
-		Repository:  fmt.Sprintf("%s/%s", owner, repo),
+		Title:            issue.GetTitle(),
+		Number:           issue.GetNumber(),
+		State:            issue.GetState(),
+		Labels:           labels,
+		Assignees:        assignees,
+		CreatedDate:      issue.GetCreatedAt().Time,
+		UpdatedDate:      issue.GetUpdatedAt().Time,
+		ClosedDate:       issue.ClosedAt.GetTime(),
+		URL:              issue.GetHTMLURL(),
+		Repository:       fmt.Sprintf("%s/%s", owner, repo),
+		JiraKeyRefs:      correlation.ExtractKeys(issue.GetTitle(), issue.GetBody()),
+		Milestone:        issue.GetMilestone().GetTitle(),
+		MilestoneDueDate: milestoneDueDate(issue.GetMilestone()),
 	}
 }
 
-// convertGitHubPR converts a GitHub pull request to our model
-// containsContent checks if an issue contains the specified text in its title, body, or comments
-func containsContent(issue *github.Issue, text string) bool {
-	// Check title
-	if issue.GetTitle() != "" && strings.Contains(strings.ToLower(issue.GetTitle()), strings.ToLower(text)) {
-		return true
-	}
-
-	// Check body
-	if issue.GetBody() != "" && strings.Contains(strings.ToLower(issue.GetBody()), strings.ToLower(text)) {
-		return true
+// milestoneDueDate returns the due date of a milestone, nil without a milestone or due date
+func milestoneDueDate(milestone *github.Milestone) *time.Time {
+	if milestone == nil {
+		return nil
 	}
-
-	// Note: Checking comments would require additional API calls
-	// This implementation just checks title and body for simplicity
-	return false
-}
-
-// containsPRContent checks if a pull request contains the specified text in its title, body, or comments
-func containsPRContent(pr *github.PullRequest, text string) bool {
-	// Check title
-	if pr.GetTitle() != "" && strings.Contains(strings.ToLower(pr.GetTitle()), strings.ToLower(text)) {
-		return true
-	}
-
-	// Check body
-	if pr.GetBody() != "" && strings.Contains(strings.ToLower(pr.GetBody()), strings.ToLower(text)) {
-		return true
-	}
-
-	// Note: Checking comments would require additional API calls
-	// This implementation just checks title and body for simplicity
-	return false
+	return milestone.DueOn.GetTime()
 }
 
+// convertGitHubPR converts a GitHub pull request to our model
 func convertGitHubPR(pr *github.PullRequest, owner, repo string) models.GitHubPR {
 	var labels []string
 	for _, label := range pr.Labels {
@@ -264,7 +512,7 @@ func convertGitHubPR(pr *github.PullRequest, owner, repo string) models.GitHubPR
 
 	// Determine merge status
 	mergeStatus := "unknown"
-	if pr.Merged != nil && *pr.Merged {
+	if pr.GetMerged() || pr.MergedAt != nil {
 		mergeStatus = "merged"
 	} else if pr.MergeableState != nil {
 		mergeStatus = *pr.MergeableState
@@ -278,9 +526,35 @@ func convertGitHubPR(pr *github.PullRequest, owner, repo string) models.GitHubPR
 		Assignees:   assignees,
 		CreatedDate: pr.GetCreatedAt().Time,
 		UpdatedDate: pr.GetUpdatedAt().Time,
+		ClosedDate:  pr.ClosedAt.GetTime(),
 		URL:         pr.GetHTMLURL(),
 		Repository:  fmt.Sprintf("%s/%s", owner, repo),
 		IsDraft:     pr.GetDraft(),
 		MergeStatus: mergeStatus,
+		HeadBranch:  pr.GetHead().GetRef(),
+		Author:      pr.GetUser().GetLogin(),
+		JiraKeyRefs: prJiraKeyRefs(pr.GetTitle(), pr.GetBody(), pr.GetHead().GetRef()),
+	}
+}
+
+// prJiraKeyRefs returns the Jira-style keys referenced by a pull request
+func prJiraKeyRefs(title, body, branch string) []string {
+	return mergeKeys(correlation.ExtractKeys(title, body), correlation.ExtractBranchKeys(branch))
+}
+
+// mergeKeys appends the keys not yet in keys
+func mergeKeys(keys, more []string) []string {
+	for _, key := range more {
+		found := false
+		for _, existing := range keys {
+			if existing == key {
+				found = true
+				break
+			}
+		}
+		if !found {
+			keys = append(keys, key)
+		}
 	}
+	return keys
 }
@@ -0,0 +1,355 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v60/github"
+	"github.com/krzko/jiragitfluence/pkg/models"
+)
+
+// snippetContext is the number of characters kept on each side of a content match
+const snippetContext = 60
+
+// timelineText is a piece of text from the timeline of an issue or pull request
+type timelineText struct {
+	Source string `json:"source"`
+	Text   string `json:"text"`
+}
+
+// commentCacheEntry holds the timeline texts of an item as of its last update
+type commentCacheEntry struct {
+	UpdatedAt time.Time      `json:"updatedAt"`
+	Texts     []timelineText `json:"texts"`
+}
+
+// commentCache caches timeline texts by item URL. Entries are reused as long as
+// the item has not been updated since they were fetched. When a path is set the
+// cache is loaded from and saved to that file, so it carries over between runs.
+type commentCache struct {
+	path   string
+	logger *slog.Logger
+
+	once    sync.Once
+	mu      sync.Mutex
+	entries map[string]commentCacheEntry
+	dirty   bool
+}
+
+// newCommentCache creates a comment cache, persisted to path when it is not empty
+func newCommentCache(path string, logger *slog.Logger) *commentCache {
+	return &commentCache{path: path, logger: logger, entries: make(map[string]commentCacheEntry)}
+}
+
+// load reads the cache file once, starting empty when it is missing or unreadable
+func (cc *commentCache) load() {
+	cc.once.Do(func() {
+		if cc.path == "" {
+			return
+		}
+		data, err := os.ReadFile(cc.path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				cc.logger.Warn("Failed to read GitHub comment cache, starting empty", "path", cc.path, "error", err)
+			}
+			return
+		}
+		if err := json.Unmarshal(data, &cc.entries); err != nil {
+			cc.logger.Warn("Failed to parse GitHub comment cache, starting empty", "path", cc.path, "error", err)
+			cc.entries = make(map[string]commentCacheEntry)
+		}
+	})
+}
+
+// get returns the cached texts of an item if they are still current
+func (cc *commentCache) get(url string, updatedAt time.Time) ([]timelineText, bool) {
+	cc.load()
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	entry, ok := cc.entries[url]
+	if !ok || !entry.UpdatedAt.Equal(updatedAt) {
+		return nil, false
+	}
+	return entry.Texts, true
+}
+
+// put stores the texts of an item
+func (cc *commentCache) put(url string, updatedAt time.Time, texts []timelineText) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.entries[url] = commentCacheEntry{UpdatedAt: updatedAt, Texts: texts}
+	cc.dirty = true
+}
+
+// save writes the cache file if anything changed
+func (cc *commentCache) save() error {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if cc.path == "" || !cc.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(cc.entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal comment cache: %w", err)
+	}
+	if dir := filepath.Dir(cc.path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create comment cache directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(cc.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write comment cache: %w", err)
+	}
+	cc.dirty = false
+	return nil
+}
+
+// SaveCommentCache writes the comment cache file, if one is configured
+func (c *Client) SaveCommentCache() error {
+	return c.comments.save()
+}
+
+// matchContent returns where filter occurs in the title or body, case-insensitively,
+// or nil when it occurs in neither
+func matchContent(filter, title, body string) *models.ContentMatch {
+	return matchTexts(filter, []timelineText{{Source: "title", Text: title}, {Source: "body", Text: body}})
+}
+
+// matchTexts returns the first text containing filter, case-insensitively
+func matchTexts(filter string, texts []timelineText) *models.ContentMatch {
+	lowerFilter := strings.ToLower(filter)
+	for _, t := range texts {
+		if t.Text == "" {
+			continue
+		}
+		// Lower-casing can change byte lengths, so search and slice the same string
+		lowerText := strings.ToLower(t.Text)
+		if idx := strings.Index(lowerText, lowerFilter); idx >= 0 {
+			return &models.ContentMatch{Source: t.Source, Snippet: snippet(lowerText, t.Text, idx, len(lowerFilter))}
+		}
+	}
+	return nil
+}
+
+// snippet returns the text around a match, with whitespace collapsed
+func snippet(lowerText, text string, idx, length int) string {
+	// Fall back to the lower-cased text when lower-casing changed its length
+	if len(lowerText) != len(text) {
+		text = lowerText
+	}
+
+	start := idx - snippetContext
+	end := idx + length + snippetContext
+	prefix, suffix := "…", "…"
+	if start <= 0 {
+		start, prefix = 0, ""
+	}
+	if end >= len(text) {
+		end, suffix = len(text), ""
+	}
+	// Avoid cutting multi-byte characters in half
+	for start > 0 && !isRuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !isRuneStart(text[end]) {
+		end++
+	}
+
+	return prefix + strings.Join(strings.Fields(text[start:end]), " ") + suffix
+}
+
+// isRuneStart reports whether b is the first byte of a UTF-8 encoded rune
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// commentCandidate is an issue or pull request whose title and body did not match the content filter
+type commentCandidate struct {
+	repository string
+	number     int
+	url        string
+	updatedAt  time.Time
+	match      *models.ContentMatch
+	err        error
+}
+
+// filterByComments searches the timeline (comments, reviews and commit messages) of
+// the items that did not match the content filter on their title or body, keeping
+// those that match there. Timelines are fetched with bounded concurrency and cached.
+// Items whose timeline cannot be fetched are skipped. If the rate limit wait
+// budget runs out, the items checked so far are returned with the error.
+func (c *Client) filterByComments(ctx context.Context, filter string, issues []models.GitHubIssue, prs []models.GitHubPR) ([]models.GitHubIssue, []models.GitHubPR, error) {
+	if filter == "" || !c.searchComments {
+		return issues, prs, nil
+	}
+
+	var candidates []*commentCandidate
+	issueCandidates := make(map[int]*commentCandidate)
+	prCandidates := make(map[int]*commentCandidate)
+	for i, issue := range issues {
+		if issue.ContentMatch == nil {
+			cand := &commentCandidate{repository: issue.Repository, number: issue.Number, url: issue.URL, updatedAt: issue.UpdatedDate}
+			candidates = append(candidates, cand)
+			issueCandidates[i] = cand
+		}
+	}
+	for i, pr := range prs {
+		if pr.ContentMatch == nil {
+			cand := &commentCandidate{repository: pr.Repository, number: pr.Number, url: pr.URL, updatedAt: pr.UpdatedDate}
+			candidates = append(candidates, cand)
+			prCandidates[i] = cand
+		}
+	}
+
+	// A fixed number of workers fetch timelines, the semaphore also bounds
+	// fetches across repositories fetched in parallel
+	work := make(chan *commentCandidate)
+	workers := cap(c.commentSem)
+	if workers > len(candidates) {
+		workers = len(candidates)
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for cand := range work {
+				c.searchCandidate(ctx, filter, cand)
+			}
+		}()
+	}
+	for _, cand := range candidates {
+		work <- cand
+	}
+	close(work)
+	wg.Wait()
+
+	var firstErr error
+	keptIssues := issues[:0]
+	for i, issue := range issues {
+		if cand, ok := issueCandidates[i]; ok {
+			if cand.err != nil && firstErr == nil {
+				firstErr = cand.err
+			}
+			if cand.match == nil {
+				continue
+			}
+			issue.ContentMatch = cand.match
+		}
+		keptIssues = append(keptIssues, issue)
+	}
+	keptPRs := prs[:0]
+	for i, pr := range prs {
+		if cand, ok := prCandidates[i]; ok {
+			if cand.err != nil && firstErr == nil {
+				firstErr = cand.err
+			}
+			if cand.match == nil {
+				continue
+			}
+			pr.ContentMatch = cand.match
+		}
+		keptPRs = append(keptPRs, pr)
+	}
+
+	c.logger.Debug("Searched GitHub comments", "checked", len(candidates), "issues", len(keptIssues), "prs", len(keptPRs))
+	if firstErr != nil {
+		// Report rate limit budget errors as such so callers keep the partial result
+		for _, cand := range candidates {
+			if errors.Is(cand.err, ErrWaitBudgetExceeded) {
+				firstErr = cand.err
+				break
+			}
+		}
+		return keptIssues, keptPRs, fmt.Errorf("failed to search comments: %w", firstErr)
+	}
+	return keptIssues, keptPRs, nil
+}
+
+// searchCandidate matches the timeline of one candidate against the filter. Items
+// whose timeline cannot be fetched, such as deleted or transferred ones, are
+// logged and left unmatched, only rate limit and cancellation errors are kept.
+func (c *Client) searchCandidate(ctx context.Context, filter string, cand *commentCandidate) {
+	select {
+	case c.commentSem <- struct{}{}:
+		defer func() { <-c.commentSem }()
+	case <-ctx.Done():
+		cand.err = ctx.Err()
+		return
+	}
+
+	texts, err := c.timelineTexts(ctx, cand)
+	if err != nil {
+		if errors.Is(err, ErrWaitBudgetExceeded) || ctx.Err() != nil {
+			cand.err = err
+			return
+		}
+		c.logger.Warn("Failed to search comments, skipping item", "repo", cand.repository, "number", cand.number, "error", err)
+		return
+	}
+	cand.match = matchTexts(filter, texts)
+}
+
+// timelineTexts returns the comment, review and commit message texts of an item,
+// using the cache when the item has not changed since it was last fetched
+func (c *Client) timelineTexts(ctx context.Context, cand *commentCandidate) ([]timelineText, error) {
+	if texts, ok := c.comments.get(cand.url, cand.updatedAt); ok {
+		return texts, nil
+	}
+
+	owner, repo, ok := strings.Cut(cand.repository, "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid repository path: %s", cand.repository)
+	}
+
+	var texts []timelineText
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		var events []*github.Timeline
+		var resp *github.Response
+		err := c.limiter.call(ctx, func() (*github.Response, error) {
+			var err error
+			events, resp, err = c.client.Issues.ListIssueTimeline(ctx, owner, repo, cand.number, opts)
+			return resp, err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch timeline of %s#%d: %w", cand.repository, cand.number, err)
+		}
+
+		for _, event := range events {
+			switch event.GetEvent() {
+			case "commented":
+				texts = append(texts, timelineText{Source: "comment by " + event.GetUser().GetLogin(), Text: event.GetBody()})
+			case "reviewed":
+				texts = append(texts, timelineText{Source: "review by " + event.GetUser().GetLogin(), Text: event.GetBody()})
+			case "committed":
+				texts = append(texts, timelineText{Source: "commit " + shortSHA(event.GetSHA()), Text: event.GetMessage()})
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	c.comments.put(cand.url, cand.updatedAt, texts)
+	return texts, nil
+}
+
+// shortSHA abbreviates a commit SHA
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,214 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/krzko/jiragitfluence/pkg/models"
+)
+
+// pullRequestDetailsQuery fetches the review, CI and commit details of a single pull request
+const pullRequestDetailsQuery = `
+query($owner: String!, $repo: String!, $number: Int!) {
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) {
+      author { login }
+      headRefName baseRefName mergedAt additions deletions reviewDecision mergeStateStatus
+      latestReviews(first: 50) { nodes { author { login } state } }
+      reviewRequests(first: 50) {
+        nodes {
+          requestedReviewer {
+            __typename
+            ... on User { login }
+            ... on Mannequin { login }
+            ... on Team { slug }
+          }
+        }
+      }
+      commits(last: 1) { nodes { commit { statusCheckRollup { state } } } }
+      closingIssuesReferences(first: 25) { nodes { number repository { nameWithOwner } } }
+    }
+  }
+}`
+
+// pullRequestDetails is the pull request returned by pullRequestDetailsQuery
+type pullRequestDetails struct {
+	Author *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+	HeadRefName      string        `json:"headRefName"`
+	BaseRefName      string        `json:"baseRefName"`
+	MergedAt         *time.Time    `json:"mergedAt"`
+	Additions        int           `json:"additions"`
+	Deletions        int           `json:"deletions"`
+	ReviewDecision   string        `json:"reviewDecision"`
+	MergeStateStatus string        `json:"mergeStateStatus"`
+	LatestReviews    latestReviews `json:"latestReviews"`
+	ReviewRequests   struct {
+		Nodes []struct {
+			RequestedReviewer *struct {
+				Typename string `json:"__typename"`
+				Login    string `json:"login"`
+				Slug     string `json:"slug"`
+			} `json:"requestedReviewer"`
+		} `json:"nodes"`
+	} `json:"reviewRequests"`
+	Commits struct {
+		Nodes []struct {
+			Commit struct {
+				StatusCheckRollup *struct {
+					State string `json:"state"`
+				} `json:"statusCheckRollup"`
+			} `json:"commit"`
+		} `json:"nodes"`
+	} `json:"commits"`
+	ClosingIssuesReferences struct {
+		Nodes []struct {
+			Number     int `json:"number"`
+			Repository struct {
+				NameWithOwner string `json:"nameWithOwner"`
+			} `json:"repository"`
+		} `json:"nodes"`
+	} `json:"closingIssuesReferences"`
+}
+
+// EnrichPullRequests fills in the review, CI and commit details of the given pull requests.
+// It does nothing unless PR enrichment is enabled. Details are fetched through the
+// GraphQL API, one request per pull request, using the configured concurrency.
+// Pull requests that cannot be enriched are logged and left as they are; if the rate
+// limit wait budget runs out, the pull requests enriched so far are kept and
+// ErrWaitBudgetExceeded is returned.
+func (c *Client) EnrichPullRequests(prs []models.GitHubPR) error {
+	if !c.enrichPRs || len(prs) == 0 {
+		return nil
+	}
+
+	workers := c.concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(prs) {
+		workers = len(prs)
+	}
+
+	c.logger.Info("Enriching GitHub pull requests", "prs", len(prs), "concurrency", workers)
+
+	ctx := context.Background()
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var failed int
+	var mu sync.Mutex
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				err := c.enrichPullRequest(ctx, &prs[i])
+				if err != nil && !errors.Is(err, ErrWaitBudgetExceeded) {
+					c.logger.Warn("Failed to enrich GitHub pull request", "repo", prs[i].Repository, "number", prs[i].Number, "error", err)
+					mu.Lock()
+					failed++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for i := range prs {
+		if c.limiter.isExhausted() {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	c.logger.Info("Enriched GitHub pull requests", "prs", len(prs), "failed", failed)
+	if c.limiter.isExhausted() {
+		return ErrWaitBudgetExceeded
+	}
+	return nil
+}
+
+// enrichPullRequest fetches the details of a single pull request into pr
+func (c *Client) enrichPullRequest(ctx context.Context, pr *models.GitHubPR) error {
+	owner, repo, ok := strings.Cut(pr.Repository, "/")
+	if !ok {
+		return fmt.Errorf("invalid repository path: %s", pr.Repository)
+	}
+
+	var data struct {
+		Repository struct {
+			PullRequest *pullRequestDetails `json:"pullRequest"`
+		} `json:"repository"`
+	}
+	variables := map[string]interface{}{
+		"owner":  owner,
+		"repo":   repo,
+		"number": pr.Number,
+	}
+	if err := c.graphQL(ctx, pullRequestDetailsQuery, variables, &data); err != nil {
+		return err
+	}
+	if data.Repository.PullRequest == nil {
+		return fmt.Errorf("pull request not found")
+	}
+
+	applyPullRequestDetails(pr, data.Repository.PullRequest)
+	return nil
+}
+
+// applyPullRequestDetails copies the fetched details onto pr
+func applyPullRequestDetails(pr *models.GitHubPR, details *pullRequestDetails) {
+	if details.Author != nil {
+		pr.Author = details.Author.Login
+	}
+	pr.HeadBranch = details.HeadRefName
+	pr.BaseBranch = details.BaseRefName
+	pr.MergedAt = details.MergedAt
+	pr.Additions = details.Additions
+	pr.Deletions = details.Deletions
+	pr.ReviewDecision = strings.ToLower(details.ReviewDecision)
+
+	// List responses lack the mergeable state, so the merge status is usually unknown until now
+	if details.MergedAt != nil {
+		pr.MergeStatus = "merged"
+	} else if details.MergeStateStatus != "" {
+		pr.MergeStatus = strings.ToLower(details.MergeStateStatus)
+	}
+
+	pr.Reviewers = nil
+	for _, review := range details.LatestReviews.Nodes {
+		if review.Author != nil && review.Author.Login != "" {
+			pr.Reviewers = append(pr.Reviewers, review.Author.Login)
+		}
+	}
+
+	pr.RequestedReviewers = nil
+	for _, request := range details.ReviewRequests.Nodes {
+		reviewer := request.RequestedReviewer
+		if reviewer == nil {
+			continue
+		}
+		if reviewer.Typename == "Team" {
+			pr.RequestedReviewers = append(pr.RequestedReviewers, "team:"+reviewer.Slug)
+		} else if reviewer.Login != "" {
+			pr.RequestedReviewers = append(pr.RequestedReviewers, reviewer.Login)
+		}
+	}
+
+	pr.CIStatus = ""
+	if nodes := details.Commits.Nodes; len(nodes) > 0 && nodes[0].Commit.StatusCheckRollup != nil {
+		pr.CIStatus = strings.ToLower(nodes[0].Commit.StatusCheckRollup.State)
+	}
+
+	pr.ClosingIssues = nil
+	for _, issue := range details.ClosingIssuesReferences.Nodes {
+		pr.ClosingIssues = append(pr.ClosingIssues, fmt.Sprintf("%s#%d", issue.Repository.NameWithOwner, issue.Number))
+	}
+}
@@ -0,0 +1,583 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v60/github"
+	"github.com/krzko/jiragitfluence/internal/config"
+	"github.com/krzko/jiragitfluence/internal/correlation"
+	"github.com/krzko/jiragitfluence/pkg/models"
+)
+
+// Supported fetch backends
+const (
+	// BackendREST fetches issues and pull requests through the REST API
+	BackendREST = "rest"
+	// BackendGraphQL fetches issues and pull requests through the GraphQL API,
+	// including Projects v2 field values
+	BackendGraphQL = "graphql"
+)
+
+// graphQLPageSize is the number of issues or pull requests requested per GraphQL page
+const graphQLPageSize = 50
+
+// projectFieldsFragment selects the Projects v2 field values attached to an issue or pull request
+const projectFieldsFragment = `
+projectItems(first: 10) {
+  nodes {
+    fieldValues(first: 30) {
+      nodes {
+        __typename
+        ... on ProjectV2ItemFieldDateValue { date field { ... on ProjectV2FieldCommon { name } } }
+        ... on ProjectV2ItemFieldTextValue { text field { ... on ProjectV2FieldCommon { name } } }
+        ... on ProjectV2ItemFieldSingleSelectValue { name field { ... on ProjectV2FieldCommon { name } } }
+        ... on ProjectV2ItemFieldIterationValue { title startDate duration field { ... on ProjectV2FieldCommon { name } } }
+      }
+    }
+  }
+}`
+
+// issuesQuery pages through the issues of a repository, see withProjectFields for the %s verb
+const issuesQuery = `
+query($owner: String!, $repo: String!, $first: Int!, $after: String, $labels: [String!], $createdBy: String, $since: DateTime) {
+  repository(owner: $owner, name: $repo) {
+    issues(first: $first, after: $after, orderBy: {field: UPDATED_AT, direction: DESC}, filterBy: {labels: $labels, createdBy: $createdBy, since: $since}) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        number title body state url createdAt updatedAt closedAt
+        labels(first: 50) { nodes { name } }
+        assignees(first: 20) { nodes { login } }
+        milestone { title dueOn }
+        %s
+      }
+    }
+  }
+}`
+
+// pullRequestsQuery pages through the pull requests of a repository, see withProjectFields for the %s verb
+const pullRequestsQuery = `
+query($owner: String!, $repo: String!, $first: Int!, $after: String, $labels: [String!]) {
+  repository(owner: $owner, name: $repo) {
+    pullRequests(first: $first, after: $after, orderBy: {field: UPDATED_AT, direction: DESC}, labels: $labels) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        number title body state url isDraft merged mergeable createdAt updatedAt closedAt headRefName reviewDecision
+        author { login }
+        labels(first: 50) { nodes { name } }
+        assignees(first: 20) { nodes { login } }
+        latestReviews(first: 50) { nodes { author { login } state } }
+        %s
+      }
+    }
+  }
+}`
+
+// graphQLRequest is the body of a GraphQL request
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// graphQLError is an error reported in a GraphQL response
+type graphQLError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// pageInfo holds GraphQL cursor pagination state
+type pageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+// namedNodes is a connection of nodes carrying a name
+type namedNodes struct {
+	Nodes []struct {
+		Name  string `json:"name"`
+		Login string `json:"login"`
+	} `json:"nodes"`
+}
+
+// projectFieldValue is a Projects v2 field value of any supported type
+type projectFieldValue struct {
+	Typename  string `json:"__typename"`
+	Date      string `json:"date"`
+	Text      string `json:"text"`
+	Name      string `json:"name"`
+	Title     string `json:"title"`
+	StartDate string `json:"startDate"`
+	Duration  int    `json:"duration"`
+	Field     struct {
+		Name string `json:"name"`
+	} `json:"field"`
+}
+
+// projectItems is the Projects v2 items attached to an issue or pull request
+type projectItems struct {
+	Nodes []struct {
+		FieldValues struct {
+			Nodes []projectFieldValue `json:"nodes"`
+		} `json:"fieldValues"`
+	} `json:"nodes"`
+}
+
+// latestReviews is the latest review of each reviewer of a pull request
+type latestReviews struct {
+	Nodes []struct {
+		Author *struct {
+			Login string `json:"login"`
+		} `json:"author"`
+		State string `json:"state"`
+	} `json:"nodes"`
+}
+
+// graphQLIssue is an issue returned by issuesQuery
+type graphQLIssue struct {
+	Number    int        `json:"number"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	State     string     `json:"state"`
+	URL       string     `json:"url"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	ClosedAt  *time.Time `json:"closedAt"`
+	Labels    namedNodes `json:"labels"`
+	Assignees namedNodes `json:"assignees"`
+	Milestone *struct {
+		Title string     `json:"title"`
+		DueOn *time.Time `json:"dueOn"`
+	} `json:"milestone"`
+	ProjectItems projectItems `json:"projectItems"`
+}
+
+// graphQLPullRequest is a pull request returned by pullRequestsQuery
+type graphQLPullRequest struct {
+	Number    int        `json:"number"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	State     string     `json:"state"`
+	URL       string     `json:"url"`
+	IsDraft   bool       `json:"isDraft"`
+	Merged    bool       `json:"merged"`
+	Mergeable string     `json:"mergeable"`
+	HeadRef   string     `json:"headRefName"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	ClosedAt  *time.Time `json:"closedAt"`
+	Labels    namedNodes `json:"labels"`
+	Assignees namedNodes `json:"assignees"`
+	Author    *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+	ReviewDecision string        `json:"reviewDecision"`
+	LatestReviews  latestReviews `json:"latestReviews"`
+	ProjectItems   projectItems  `json:"projectItems"`
+}
+
+// withProjectFields fills the project fields selection of a query. Project items
+// are only selected when project fields are configured, since reading them
+// requires the read:project scope and fails the whole query without it.
+func (c *Client) withProjectFields(query string) string {
+	selection := ""
+	if c.projectFields != (config.GitHubProjectFields{}) {
+		selection = projectFieldsFragment
+	}
+	return fmt.Sprintf(query, selection)
+}
+
+// graphQL executes a GraphQL query and decodes its data into out.
+// Rate limit errors are reported as go-github errors so the rate limiter can handle them.
+func (c *Client) graphQL(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	return c.limiter.call(ctx, func() (*github.Response, error) {
+		req, err := c.client.NewRequest("POST", "graphql", graphQLRequest{Query: query, Variables: variables})
+		if err != nil {
+			return nil, err
+		}
+
+		var body struct {
+			Data   interface{}    `json:"data"`
+			Errors []graphQLError `json:"errors"`
+		}
+		body.Data = out
+
+		resp, err := c.client.Do(ctx, req, &body)
+		if err != nil {
+			return resp, err
+		}
+
+		if len(body.Errors) > 0 {
+			for _, e := range body.Errors {
+				if e.Type == "RATE_LIMITED" {
+					return resp, &github.RateLimitError{Rate: resp.Rate, Response: resp.Response, Message: e.Message}
+				}
+			}
+			msgs := make([]string, 0, len(body.Errors))
+			for _, e := range body.Errors {
+				msgs = append(msgs, e.Message)
+			}
+			return resp, fmt.Errorf("GraphQL query failed: %s", strings.Join(msgs, "; "))
+		}
+
+		return resp, nil
+	})
+}
+
+// fetchIssuesGraphQL fetches issues from a GitHub repository through the GraphQL API
+func (c *Client) fetchIssuesGraphQL(ctx context.Context, owner, repo string, labels []string, contentFilter string, creator string, dates DateFilter) ([]models.GitHubIssue, error) {
+	var allIssues []models.GitHubIssue
+
+	variables := map[string]interface{}{
+		"owner": owner,
+		"repo":  repo,
+		"first": graphQLPageSize,
+	}
+	if len(labels) > 0 {
+		variables["labels"] = labels
+	}
+	if creator != "" {
+		variables["createdBy"] = creator
+	}
+	if !dates.UpdatedAfter.IsZero() {
+		variables["since"] = dates.UpdatedAfter.UTC().Format(time.RFC3339)
+	}
+
+	for {
+		var data struct {
+			Repository *struct {
+				Issues struct {
+					PageInfo pageInfo       `json:"pageInfo"`
+					Nodes    []graphQLIssue `json:"nodes"`
+				} `json:"issues"`
+			} `json:"repository"`
+		}
+		if err := c.graphQL(ctx, c.withProjectFields(issuesQuery), variables, &data); err != nil {
+			return allIssues, err
+		}
+		if data.Repository == nil {
+			return nil, fmt.Errorf("repository %s/%s not found", owner, repo)
+		}
+
+		for _, issue := range data.Repository.Issues.Nodes {
+			issueLabels := nodeNames(issue.Labels)
+
+			// The GraphQL label filter matches any label
+			if !c.hasLabels(issueLabels, labels) {
+				continue
+			}
+
+			if dates.closedBefore(issue.ClosedAt) {
+				continue
+			}
+
+			// Apply content filter if specified, leaving items for the comment search when enabled
+			var match *models.ContentMatch
+			if contentFilter != "" {
+				match = matchContent(contentFilter, issue.Title, issue.Body)
+				if match == nil && !c.searchComments {
+					continue
+				}
+			}
+
+			ghIssue := c.convertGraphQLIssue(issue, issueLabels, owner, repo)
+			ghIssue.ContentMatch = match
+			allIssues = append(allIssues, ghIssue)
+		}
+
+		pi := data.Repository.Issues.PageInfo
+		if !pi.HasNextPage {
+			break
+		}
+		variables["after"] = pi.EndCursor
+	}
+
+	c.logger.Info("Fetched GitHub issues", "repo", fmt.Sprintf("%s/%s", owner, repo), "count", len(allIssues), "backend", BackendGraphQL)
+	return allIssues, nil
+}
+
+// fetchPullRequestsGraphQL fetches pull requests from a GitHub repository through the GraphQL API
+func (c *Client) fetchPullRequestsGraphQL(ctx context.Context, owner, repo string, labels []string, contentFilter string, creator string, dates DateFilter) ([]models.GitHubPR, error) {
+	var allPRs []models.GitHubPR
+
+	variables := map[string]interface{}{
+		"owner": owner,
+		"repo":  repo,
+		"first": graphQLPageSize,
+	}
+	if len(labels) > 0 {
+		variables["labels"] = labels
+	}
+
+	reachedCutoff := false
+	for !reachedCutoff {
+		var data struct {
+			Repository *struct {
+				PullRequests struct {
+					PageInfo pageInfo             `json:"pageInfo"`
+					Nodes    []graphQLPullRequest `json:"nodes"`
+				} `json:"pullRequests"`
+			} `json:"repository"`
+		}
+		if err := c.graphQL(ctx, c.withProjectFields(pullRequestsQuery), variables, &data); err != nil {
+			return allPRs, err
+		}
+		if data.Repository == nil {
+			return nil, fmt.Errorf("repository %s/%s not found", owner, repo)
+		}
+
+		for _, pr := range data.Repository.PullRequests.Nodes {
+			// Pull requests are sorted by update time, so the rest are older than the cutoff
+			if dates.updatedBefore(pr.UpdatedAt) {
+				reachedCutoff = true
+				break
+			}
+
+			if dates.closedBefore(pr.ClosedAt) {
+				continue
+			}
+
+			// The GraphQL label filter matches any label
+			if !c.hasLabels(nodeNames(pr.Labels), labels) {
+				continue
+			}
+
+			// Apply creator filter if specified, pull requests of deleted users have no author
+			if creator != "" && (pr.Author == nil || pr.Author.Login != creator) {
+				continue
+			}
+
+			// Apply content filter if specified, leaving items for the comment search when enabled
+			var match *models.ContentMatch
+			if contentFilter != "" {
+				match = matchContent(contentFilter, pr.Title, pr.Body)
+				if match == nil && !c.searchComments {
+					continue
+				}
+			}
+
+			ghPR := c.convertGraphQLPR(pr, owner, repo)
+			ghPR.ContentMatch = match
+			allPRs = append(allPRs, ghPR)
+		}
+
+		pi := data.Repository.PullRequests.PageInfo
+		if reachedCutoff || !pi.HasNextPage {
+			break
+		}
+		variables["after"] = pi.EndCursor
+	}
+
+	c.logger.Info("Fetched GitHub pull requests", "repo", fmt.Sprintf("%s/%s", owner, repo), "count", len(allPRs), "backend", BackendGraphQL)
+	return allPRs, nil
+}
+
+// convertGraphQLIssue converts a GraphQL issue to our model, reading roadmap
+// fields from the configured Projects v2 fields
+func (c *Client) convertGraphQLIssue(issue graphQLIssue, labels []string, owner, repo string) models.GitHubIssue {
+	ghIssue := models.GitHubIssue{
+		Title:       issue.Title,
+		Number:      issue.Number,
+		State:       strings.ToLower(issue.State),
+		Labels:      labels,
+		Assignees:   nodeLogins(issue.Assignees),
+		CreatedDate: issue.CreatedAt,
+		UpdatedDate: issue.UpdatedAt,
+		ClosedDate:  issue.ClosedAt,
+		URL:         issue.URL,
+		Repository:  fmt.Sprintf("%s/%s", owner, repo),
+		JiraKeyRefs: correlation.ExtractKeys(issue.Title, issue.Body),
+	}
+
+	// The repository milestone is the fallback for the milestone and planned end date
+	if issue.Milestone != nil {
+		ghIssue.Milestone = issue.Milestone.Title
+		ghIssue.MilestoneDueDate = issue.Milestone.DueOn
+	}
+
+	roadmap := c.projectRoadmap(issue.ProjectItems)
+	if roadmap.start != nil {
+		ghIssue.PlannedStartDate = roadmap.start
+	}
+	if roadmap.end != nil {
+		ghIssue.PlannedEndDate = roadmap.end
+	}
+	if roadmap.milestone != "" {
+		ghIssue.Milestone = roadmap.milestone
+	}
+	if roadmap.theme != "" {
+		ghIssue.Theme = roadmap.theme
+	}
+
+	// Labels, issue forms and the milestone fill what the project fields left unset
+	c.applyRoadmapFields(&ghIssue, issue.Body)
+
+	return ghIssue
+}
+
+// projectRoadmap holds the roadmap fields read from Projects v2 field values
+type projectRoadmap struct {
+	start     *time.Time
+	end       *time.Time
+	milestone string
+	theme     string
+}
+
+// projectRoadmap reads the configured roadmap fields from the Projects v2 items of an issue or pull request
+func (c *Client) projectRoadmap(items projectItems) projectRoadmap {
+	var roadmap projectRoadmap
+	fields := c.projectFields
+	for _, item := range items.Nodes {
+		for _, value := range item.FieldValues.Nodes {
+			name := value.Field.Name
+			if name == "" {
+				continue
+			}
+
+			switch {
+			case strings.EqualFold(name, fields.PlannedStartDate):
+				if start, _ := projectValueDates(value); start != nil {
+					roadmap.start = start
+				}
+			case strings.EqualFold(name, fields.PlannedEndDate):
+				if start, end := projectValueDates(value); end != nil {
+					roadmap.end = end
+				} else if start != nil {
+					roadmap.end = start
+				}
+			case strings.EqualFold(name, fields.Milestone):
+				if s := projectValueString(value); s != "" {
+					roadmap.milestone = s
+				}
+			case strings.EqualFold(name, fields.Theme):
+				if s := projectValueString(value); s != "" {
+					roadmap.theme = s
+				}
+			}
+		}
+	}
+	return roadmap
+}
+
+// convertGraphQLPR converts a GraphQL pull request to our model, including its
+// reviews and the roadmap fields of the configured Projects v2 fields
+func (c *Client) convertGraphQLPR(pr graphQLPullRequest, owner, repo string) models.GitHubPR {
+	// Match the REST API, where merged pull requests are closed
+	state := strings.ToLower(pr.State)
+	mergeStatus := "unknown"
+	switch {
+	case pr.Merged:
+		state = "closed"
+		mergeStatus = "merged"
+	case pr.Mergeable == "MERGEABLE":
+		mergeStatus = "clean"
+	case pr.Mergeable == "CONFLICTING":
+		mergeStatus = "dirty"
+	}
+
+	author := ""
+	if pr.Author != nil {
+		author = pr.Author.Login
+	}
+
+	var reviewers []string
+	for _, review := range pr.LatestReviews.Nodes {
+		if review.Author != nil && review.Author.Login != "" {
+			reviewers = append(reviewers, review.Author.Login)
+		}
+	}
+
+	roadmap := c.projectRoadmap(pr.ProjectItems)
+
+	return models.GitHubPR{
+		Title:       pr.Title,
+		Number:      pr.Number,
+		State:       state,
+		Labels:      nodeNames(pr.Labels),
+		Assignees:   nodeLogins(pr.Assignees),
+		CreatedDate: pr.CreatedAt,
+		UpdatedDate: pr.UpdatedAt,
+		ClosedDate:  pr.ClosedAt,
+		URL:         pr.URL,
+		Repository:  fmt.Sprintf("%s/%s", owner, repo),
+		IsDraft:     pr.IsDraft,
+		MergeStatus: mergeStatus,
+		HeadBranch:  pr.HeadRef,
+		Author:      author,
+		JiraKeyRefs: prJiraKeyRefs(pr.Title, pr.Body, pr.HeadRef),
+
+		ReviewDecision:   strings.ToLower(pr.ReviewDecision),
+		Reviewers:        reviewers,
+		PlannedStartDate: roadmap.start,
+		PlannedEndDate:   roadmap.end,
+		Milestone:        roadmap.milestone,
+		Theme:            roadmap.theme,
+	}
+}
+
+// projectValueDates returns the start and end dates of a date or iteration field value
+func projectValueDates(value projectFieldValue) (*time.Time, *time.Time) {
+	switch value.Typename {
+	case "ProjectV2ItemFieldDateValue":
+		if t, err := time.Parse("2006-01-02", value.Date); err == nil {
+			return &t, &t
+		}
+	case "ProjectV2ItemFieldIterationValue":
+		if t, err := time.Parse("2006-01-02", value.StartDate); err == nil {
+			end := t.AddDate(0, 0, value.Duration)
+			return &t, &end
+		}
+	}
+	return nil, nil
+}
+
+// projectValueString returns the display value of a text, single select or iteration field value
+func projectValueString(value projectFieldValue) string {
+	switch value.Typename {
+	case "ProjectV2ItemFieldTextValue":
+		return value.Text
+	case "ProjectV2ItemFieldSingleSelectValue":
+		return value.Name
+	case "ProjectV2ItemFieldIterationValue":
+		return value.Title
+	case "ProjectV2ItemFieldDateValue":
+		return value.Date
+	}
+	return ""
+}
+
+// nodeNames returns the names of a connection's nodes
+func nodeNames(nodes namedNodes) []string {
+	var names []string
+	for _, n := range nodes.Nodes {
+		names = append(names, n.Name)
+	}
+	return names
+}
+
+// nodeLogins returns the logins of a connection's nodes
+func nodeLogins(nodes namedNodes) []string {
+	var logins []string
+	for _, n := range nodes.Nodes {
+		logins = append(logins, n.Login)
+	}
+	return logins
+}
+
+// hasAllLabels checks if all filter labels are present
+func hasAllLabels(labels []string, filterLabels []string) bool {
+	for _, filterLabel := range filterLabels {
+		found := false
+		for _, label := range labels {
+			if label == filterLabel {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
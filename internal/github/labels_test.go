@@ -0,0 +1,163 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/krzko/jiragitfluence/internal/config"
+)
+
+// labelledItems are the labels of the issues and of the pull requests served by
+// the fake GitHub API, the title of each item names its labels
+var labelledItems = [][]string{{"a"}, {"b"}, {"a", "b"}, {}}
+
+// searchLabelPattern matches a label qualifier of a search query
+var searchLabelPattern = regexp.MustCompile(`label:(\S+)`)
+
+// newLabelTestClient returns a client talking to a fake GitHub API that applies
+// label filters the way GitHub does: the issue list requires all of the labels,
+// a search query requires each label qualifier, any of a comma-separated list.
+func newLabelTestClient(t *testing.T, labelMatch string) *Client {
+	t.Helper()
+
+	item := func(number int, labels []string, pr bool) map[string]interface{} {
+		names := make([]map[string]string, 0, len(labels))
+		for _, label := range labels {
+			names = append(names, map[string]string{"name": label})
+		}
+		kind := "issues"
+		if pr {
+			kind = "pull"
+		}
+		item := map[string]interface{}{
+			"number":     number,
+			"title":      "labels:" + strings.Join(labels, ","),
+			"state":      "open",
+			"labels":     names,
+			"html_url":   fmt.Sprintf("https://github.com/o/r/%s/%d", kind, number),
+			"updated_at": time.Now().Format(time.RFC3339),
+			"node_id":    fmt.Sprintf("node%d", number),
+		}
+		if pr {
+			item["pull_request"] = map[string]string{"url": fmt.Sprintf("https://api.github.com/repos/o/r/pulls/%d", number)}
+		}
+		return item
+	}
+	hasLabel := func(labels []string, want string) bool {
+		for _, label := range labels {
+			if label == want {
+				return true
+			}
+		}
+		return false
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/o/r/issues", func(w http.ResponseWriter, r *http.Request) {
+		var filter []string
+		if v := r.URL.Query().Get("labels"); v != "" {
+			filter = strings.Split(v, ",")
+		}
+		items := []map[string]interface{}{}
+		for i, labels := range labelledItems {
+			matches := true
+			for _, want := range filter {
+				matches = matches && hasLabel(labels, want)
+			}
+			if matches {
+				items = append(items, item(i+1, labels, false))
+			}
+		}
+		json.NewEncoder(w).Encode(items)
+	})
+	mux.HandleFunc("/search/issues", func(w http.ResponseWriter, r *http.Request) {
+		qualifiers := searchLabelPattern.FindAllStringSubmatch(r.URL.Query().Get("q"), -1)
+		items := []map[string]interface{}{}
+		for i, labels := range labelledItems {
+			matches := true
+			for _, qualifier := range qualifiers {
+				any := false
+				for _, want := range strings.Split(qualifier[1], ",") {
+					any = any || hasLabel(labels, strings.Trim(want, `"`))
+				}
+				matches = matches && any
+			}
+			if matches {
+				items = append(items, item(100+i, labels, true))
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"total_count": len(items), "items": items})
+	})
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":{"nodes":[]}}`)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := NewClient(config.GitHubConfig{LabelMatch: labelMatch}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.client.BaseURL = baseURL
+	return client
+}
+
+func TestLabelMatchIssuesAndPullRequests(t *testing.T) {
+	tests := []struct {
+		name       string
+		labelMatch string
+		labels     []string
+		want       []string
+	}{
+		{"default requires all labels", "", []string{"a", "b"}, []string{"labels:a,b"}},
+		{"all", config.LabelMatchAll, []string{"a", "b"}, []string{"labels:a,b"}},
+		{"any", config.LabelMatchAny, []string{"a", "b"}, []string{"labels:a", "labels:a,b", "labels:b"}},
+		{"all with one label", config.LabelMatchAll, []string{"a"}, []string{"labels:a", "labels:a,b"}},
+		{"any with one label", config.LabelMatchAny, []string{"a"}, []string{"labels:a", "labels:a,b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newLabelTestClient(t, tt.labelMatch)
+			ctx := context.Background()
+
+			issues, err := client.fetchIssues(ctx, "o", "r", tt.labels, "", "", DateFilter{})
+			if err != nil {
+				t.Fatalf("fetchIssues() error = %v", err)
+			}
+			prs, err := client.fetchPullRequests(ctx, "o", "r", tt.labels, "", "", DateFilter{})
+			if err != nil {
+				t.Fatalf("fetchPullRequests() error = %v", err)
+			}
+
+			var issueTitles, prTitles []string
+			for _, issue := range issues {
+				issueTitles = append(issueTitles, issue.Title)
+			}
+			for _, pr := range prs {
+				prTitles = append(prTitles, pr.Title)
+			}
+			sort.Strings(issueTitles)
+			sort.Strings(prTitles)
+
+			if strings.Join(issueTitles, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("issues = %v, want %v", issueTitles, tt.want)
+			}
+			if strings.Join(prTitles, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("pull requests = %v, want %v", prTitles, tt.want)
+			}
+		})
+	}
+}
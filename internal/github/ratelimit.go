@@ -0,0 +1,137 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v60/github"
+)
+
+// ErrWaitBudgetExceeded is returned when waiting for a rate limit reset would
+// exceed the configured maximum wait
+var ErrWaitBudgetExceeded = errors.New("GitHub rate limit wait budget exceeded")
+
+// defaultSecondaryRetryAfter is used when a secondary rate limit response carries no Retry-After
+const defaultSecondaryRetryAfter = time.Minute
+
+// lowQuotaThreshold is the fraction of the quota below which the remaining requests are logged as a warning
+const lowQuotaThreshold = 0.1
+
+// rateLimiter retries GitHub API calls that hit primary or secondary rate limits,
+// sleeping until the limit resets as long as the total wait stays within maxWait
+type rateLimiter struct {
+	maxWait time.Duration
+	logger  *slog.Logger
+
+	mu        sync.Mutex
+	waited    time.Duration
+	waitUntil time.Time
+	exhausted bool
+}
+
+// newRateLimiter creates a rate limiter with the given wait budget
+func newRateLimiter(maxWait time.Duration, logger *slog.Logger) *rateLimiter {
+	return &rateLimiter{
+		maxWait: maxWait,
+		logger:  logger,
+	}
+}
+
+// call runs fn, waiting and retrying while it fails with a rate limit error
+func (r *rateLimiter) call(ctx context.Context, fn func() (*github.Response, error)) error {
+	for {
+		if r.isExhausted() {
+			return ErrWaitBudgetExceeded
+		}
+
+		resp, err := fn()
+		if resp != nil {
+			r.logQuota(resp.Rate)
+		}
+
+		var wait time.Duration
+		var rateErr *github.RateLimitError
+		var abuseErr *github.AbuseRateLimitError
+		switch {
+		case errors.As(err, &rateErr):
+			wait = time.Until(rateErr.Rate.Reset.Time) + time.Second
+			r.logger.Warn("GitHub primary rate limit reached", "reset", rateErr.Rate.Reset.Time, "wait", wait)
+		case errors.As(err, &abuseErr):
+			wait = defaultSecondaryRetryAfter
+			if abuseErr.RetryAfter != nil {
+				wait = *abuseErr.RetryAfter
+			}
+			r.logger.Warn("GitHub secondary rate limit reached", "retry_after", wait)
+		default:
+			return err
+		}
+
+		if err := r.wait(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// wait sleeps for d unless doing so would exceed the wait budget. Waits of concurrent
+// callers overlap, so only the time beyond the furthest pending wait is charged.
+func (r *rateLimiter) wait(ctx context.Context, d time.Duration) error {
+	if d < 0 {
+		d = 0
+	}
+
+	r.mu.Lock()
+	now := time.Now()
+	until := now.Add(d)
+	charge := d
+	if r.waitUntil.After(now) {
+		charge = until.Sub(r.waitUntil)
+		if charge < 0 {
+			charge = 0
+		}
+	}
+	if r.waited+charge > r.maxWait {
+		r.exhausted = true
+		r.mu.Unlock()
+		r.logger.Error("GitHub rate limit wait budget exceeded", "waited", r.waited, "required", charge, "max_wait", r.maxWait)
+		return fmt.Errorf("%w (waited %s of %s)", ErrWaitBudgetExceeded, r.waited, r.maxWait)
+	}
+	r.waited += charge
+	if until.After(r.waitUntil) {
+		r.waitUntil = until
+	}
+	r.mu.Unlock()
+
+	r.logger.Info("Waiting for GitHub rate limit", "wait", d, "until", until)
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// isExhausted reports whether the wait budget has run out
+func (r *rateLimiter) isExhausted() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.exhausted
+}
+
+// logQuota logs the remaining request quota, warning when it is running low
+func (r *rateLimiter) logQuota(rate github.Rate) {
+	if rate.Limit == 0 {
+		return
+	}
+	if float64(rate.Remaining) < float64(rate.Limit)*lowQuotaThreshold {
+		r.logger.Warn("GitHub rate limit quota low", "remaining", rate.Remaining, "limit", rate.Limit, "reset", rate.Reset.Time)
+		return
+	}
+	r.logger.Debug("GitHub rate limit quota", "remaining", rate.Remaining, "limit", rate.Limit, "reset", rate.Reset.Time)
+}
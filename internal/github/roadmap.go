@@ -0,0 +1,153 @@
+package github
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/krzko/jiragitfluence/pkg/models"
+)
+
+// formDateLayouts are the date formats accepted in issue form sections
+var formDateLayouts = []string{
+	"2006-01-02",
+	time.RFC3339,
+	"2006/01/02",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+}
+
+// quarterPattern matches quarters such as "Q1 2025", "2025-Q1", "q1-2025" or "2025Q1"
+var quarterPattern = regexp.MustCompile(`(?i)^(?:q([1-4])[\s\-/]*(\d{4})|(\d{4})[\s\-/]*q([1-4]))$`)
+
+// priorityNames maps common priority label values to priority scores
+var priorityNames = map[string]int{
+	"p0": 100, "critical": 100, "urgent": 100,
+	"p1": 75, "high": 75,
+	"p2": 50, "medium": 50,
+	"p3": 25, "low": 25,
+	"p4": 10, "lowest": 10,
+}
+
+// applyRoadmapFields fills the roadmap fields of an issue that are not already set,
+// reading theme, quarter and priority from prefixed labels and planned dates from
+// issue form sections in the body. The milestone due date is the fallback for the
+// planned end date, and the planned start date for the quarter.
+func (c *Client) applyRoadmapFields(issue *models.GitHubIssue, body string) {
+	if issue.Theme == "" {
+		issue.Theme = labelValue(issue.Labels, c.labelFields.Theme)
+	}
+	if issue.Quarter == "" {
+		issue.Quarter = normalizeQuarter(labelValue(issue.Labels, c.labelFields.Quarter))
+	}
+	if issue.PriorityScore == 0 {
+		issue.PriorityScore = parsePriority(labelValue(issue.Labels, c.labelFields.Priority))
+	}
+
+	if issue.PlannedStartDate == nil {
+		issue.PlannedStartDate = parseFormDate(formSection(body, c.formFields.PlannedStartDate))
+	}
+	if issue.PlannedEndDate == nil {
+		issue.PlannedEndDate = parseFormDate(formSection(body, c.formFields.PlannedEndDate))
+	}
+	if issue.PlannedEndDate == nil && issue.MilestoneDueDate != nil {
+		issue.PlannedEndDate = issue.MilestoneDueDate
+	}
+
+	if issue.Quarter == "" && issue.PlannedStartDate != nil {
+		quarterNum := (int(issue.PlannedStartDate.Month())-1)/3 + 1
+		issue.Quarter = fmt.Sprintf("Q%d %d", quarterNum, issue.PlannedStartDate.Year())
+	}
+}
+
+// labelValue returns the rest of the first label starting with prefix, case-insensitively
+func labelValue(labels []string, prefix string) string {
+	if prefix == "" {
+		return ""
+	}
+	for _, label := range labels {
+		if len(label) > len(prefix) && strings.EqualFold(label[:len(prefix)], prefix) {
+			if value := strings.TrimSpace(label[len(prefix):]); value != "" {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// normalizeQuarter rewrites recognised quarters as "Q1 2025", leaving other values as they are
+func normalizeQuarter(value string) string {
+	m := quarterPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil {
+		return value
+	}
+	if m[1] != "" {
+		return fmt.Sprintf("Q%s %s", m[1], m[2])
+	}
+	return fmt.Sprintf("Q%s %s", m[4], m[3])
+}
+
+// parsePriority converts a priority label value to a score from 1 to 100.
+// Numbers are used as they are, names such as P1 or high are mapped.
+func parsePriority(value string) int {
+	if value == "" {
+		return 0
+	}
+	if n, err := strconv.Atoi(value); err == nil {
+		if n < 1 {
+			return 1
+		}
+		if n > 100 {
+			return 100
+		}
+		return n
+	}
+	return priorityNames[strings.ToLower(value)]
+}
+
+// formSection returns the value of an issue form field, which GitHub renders in the
+// issue body as a "### <label>" heading followed by the answer
+func formSection(body, label string) string {
+	if body == "" || label == "" {
+		return ""
+	}
+
+	lines := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		heading, ok := strings.CutPrefix(strings.TrimSpace(line), "###")
+		if !ok || !strings.EqualFold(strings.TrimSpace(heading), label) {
+			continue
+		}
+
+		var value []string
+		for _, next := range lines[i+1:] {
+			if strings.HasPrefix(strings.TrimSpace(next), "###") {
+				break
+			}
+			value = append(value, next)
+		}
+		section := strings.TrimSpace(strings.Join(value, "\n"))
+		if section == "_No response_" {
+			return ""
+		}
+		return section
+	}
+	return ""
+}
+
+// parseFormDate parses a date entered in an issue form, returning nil when it is not a date
+func parseFormDate(value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+	for _, layout := range formDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,488 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v60/github"
+	"github.com/krzko/jiragitfluence/internal/correlation"
+	"github.com/krzko/jiragitfluence/pkg/models"
+)
+
+// searchResultLimit is the maximum number of results the GitHub Search API returns for a query
+const searchResultLimit = 1000
+
+// errSearchResultLimit is returned when a pull request search matches more results
+// than the Search API returns, so the caller lists the pull requests instead
+var errSearchResultLimit = errors.New("search matches more results than the Search API returns")
+
+// headBranchBatchSize is the number of pull requests whose head branch is looked up per GraphQL query
+const headBranchBatchSize = 100
+
+// headBranchQuery looks up the head branch of pull requests by node ID, which
+// the Search API does not return
+const headBranchQuery = `
+query($ids: [ID!]!) {
+  nodes(ids: $ids) {
+    ... on PullRequest { id headRefName }
+  }
+}`
+
+// ResolveRepos expands repository specs into explicit owner/repo paths.
+// Supported specs are:
+//   - owner/repo: used as-is
+//   - org:acme or user:octocat: all repositories of the organisation or user
+//   - a repository search query scoped to an owner (e.g. "topic:platform org:acme"):
+//     matching repositories
+//   - an issue search query containing is:issue, is:pr, type:issue or type:pr: returned
+//     unchanged in queries, to be run with SearchIssuesAndPRs
+//
+// Repositories are returned in first-seen order without duplicates.
+func (c *Client) ResolveRepos(specs []string) ([]string, []string, error) {
+	ctx := context.Background()
+
+	var repos, queries []string
+	seen := make(map[string]bool)
+	add := func(repoPath string) {
+		if !seen[repoPath] {
+			seen[repoPath] = true
+			repos = append(repos, repoPath)
+		}
+	}
+
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		switch {
+		case spec == "":
+			continue
+		case isIssueSearchQuery(spec):
+			queries = append(queries, spec)
+		case strings.HasPrefix(spec, "org:") && !strings.ContainsAny(spec, " \t"):
+			orgRepos, err := c.listOrgRepos(ctx, strings.TrimPrefix(spec, "org:"))
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to list repositories for %s: %w", spec, err)
+			}
+			for _, r := range orgRepos {
+				add(r)
+			}
+		case strings.HasPrefix(spec, "user:") && !strings.ContainsAny(spec, " \t"):
+			userRepos, err := c.listUserRepos(ctx, strings.TrimPrefix(spec, "user:"))
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to list repositories for %s: %w", spec, err)
+			}
+			for _, r := range userRepos {
+				add(r)
+			}
+		case strings.Contains(spec, ":"):
+			// Unscoped searches run across all of GitHub and match unrelated public repositories
+			if !hasOwnerQualifier(spec) {
+				return nil, nil, fmt.Errorf("repository search %q must be scoped with an org: or user: qualifier, e.g. %q", spec, spec+" org:acme")
+			}
+			found, err := c.searchRepos(ctx, spec)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to search repositories for %q: %w", spec, err)
+			}
+			for _, r := range found {
+				add(r)
+			}
+		default:
+			add(spec)
+		}
+	}
+
+	c.logger.Info("Resolved GitHub repositories", "specs", specs, "repos", len(repos), "queries", len(queries))
+	return repos, queries, nil
+}
+
+// hasOwnerQualifier reports whether a search query is limited to an organisation or user
+func hasOwnerQualifier(spec string) bool {
+	for _, term := range strings.Fields(spec) {
+		term = strings.ToLower(term)
+		if strings.HasPrefix(term, "org:") || strings.HasPrefix(term, "user:") {
+			return true
+		}
+	}
+	return false
+}
+
+// isIssueSearchQuery reports whether a spec is a search query for issues or pull requests
+func isIssueSearchQuery(spec string) bool {
+	for _, term := range strings.Fields(spec) {
+		switch strings.ToLower(term) {
+		case "is:issue", "is:pr", "type:issue", "type:pr":
+			return true
+		}
+	}
+	return false
+}
+
+// listOrgRepos lists all repositories of an organisation
+func (c *Client) listOrgRepos(ctx context.Context, org string) ([]string, error) {
+	var repos []string
+	opts := &github.RepositoryListByOrgOptions{
+		Type:        "all",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	for {
+		var page []*github.Repository
+		var resp *github.Response
+		err := c.limiter.call(ctx, func() (*github.Response, error) {
+			var err error
+			page, resp, err = c.client.Repositories.ListByOrg(ctx, org, opts)
+			return resp, err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range page {
+			repos = append(repos, r.GetFullName())
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return repos, nil
+}
+
+// listUserRepos lists all repositories owned by a user
+func (c *Client) listUserRepos(ctx context.Context, user string) ([]string, error) {
+	var repos []string
+	opts := &github.RepositoryListByUserOptions{
+		Type:        "owner",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	for {
+		var page []*github.Repository
+		var resp *github.Response
+		err := c.limiter.call(ctx, func() (*github.Response, error) {
+			var err error
+			page, resp, err = c.client.Repositories.ListByUser(ctx, user, opts)
+			return resp, err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range page {
+			repos = append(repos, r.GetFullName())
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return repos, nil
+}
+
+// searchRepos returns the repositories matching a repository search query
+func (c *Client) searchRepos(ctx context.Context, query string) ([]string, error) {
+	var repos []string
+	opts := &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 100}}
+
+	for {
+		var result *github.RepositoriesSearchResult
+		var resp *github.Response
+		err := c.limiter.call(ctx, func() (*github.Response, error) {
+			var err error
+			result, resp, err = c.client.Search.Repositories(ctx, query, opts)
+			return resp, err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range result.Repositories {
+			repos = append(repos, r.GetFullName())
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	if len(repos) >= searchResultLimit {
+		c.logger.Warn("Repository search hit the Search API result limit", "query", query, "limit", searchResultLimit)
+	}
+
+	return repos, nil
+}
+
+// SearchIssuesAndPRs runs issue search queries and converts the results.
+// The label, content and creator filters are applied to the results in the same
+// way as FetchIssuesAndPRs applies them.
+func (c *Client) SearchIssuesAndPRs(queries []string, labels []string, contentFilter string, creator string, dates DateFilter) ([]models.GitHubIssue, []models.GitHubPR, error) {
+	ctx := context.Background()
+
+	var issues []models.GitHubIssue
+	var prs []models.GitHubPR
+	var prNodeIDs []string
+
+	for _, query := range queries {
+		query += dates.searchQualifiers()
+		c.logger.Info("Searching GitHub issues", "query", query)
+
+		opts := &github.SearchOptions{
+			Sort:        "updated",
+			Order:       "desc",
+			ListOptions: github.ListOptions{PerPage: 100},
+		}
+		count := 0
+
+		for {
+			var result *github.IssuesSearchResult
+			var resp *github.Response
+			err := c.limiter.call(ctx, func() (*github.Response, error) {
+				var err error
+				result, resp, err = c.client.Search.Issues(ctx, query, opts)
+				return resp, err
+			})
+			if err != nil {
+				// Keep the results so far, settling items that are waiting for the comment search
+				issues, prs, _ = c.filterByComments(ctx, contentFilter, issues, prs)
+				return issues, prs, fmt.Errorf("failed to search issues for %q: %w", query, err)
+			}
+
+			for _, issue := range result.Issues {
+				if creator != "" && issue.GetUser().GetLogin() != creator {
+					continue
+				}
+				if dates.closedBefore(issue.ClosedAt.GetTime()) {
+					continue
+				}
+				var match *models.ContentMatch
+				if contentFilter != "" {
+					match = matchContent(contentFilter, issue.GetTitle(), issue.GetBody())
+					if match == nil && !c.searchComments {
+						continue
+					}
+				}
+
+				owner, repo := repoFromURL(issue.GetRepositoryURL())
+				if issue.IsPullRequest() {
+					if !c.hasLabels(labelNames(issue.Labels), labels) {
+						continue
+					}
+					pr := convertSearchPR(issue, owner, repo)
+					pr.ContentMatch = match
+					prs = append(prs, pr)
+					prNodeIDs = append(prNodeIDs, issue.GetNodeID())
+				} else {
+					if !c.hasLabels(labelNames(issue.Labels), labels) {
+						continue
+					}
+					ghIssue := convertGitHubIssue(issue, owner, repo)
+					c.applyRoadmapFields(&ghIssue, issue.GetBody())
+					ghIssue.ContentMatch = match
+					issues = append(issues, ghIssue)
+				}
+				count++
+			}
+
+			if resp.NextPage == 0 {
+				break
+			}
+			opts.Page = resp.NextPage
+		}
+
+		if count >= searchResultLimit {
+			c.logger.Warn("Issue search hit the Search API result limit", "query", query, "limit", searchResultLimit)
+		}
+		c.logger.Info("Fetched GitHub search results", "query", query, "count", count)
+	}
+
+	c.fillHeadBranches(ctx, prs, prNodeIDs)
+
+	// Search the comments of items whose title and body did not match
+	return c.filterByComments(ctx, contentFilter, issues, prs)
+}
+
+// searchPullRequests fetches the pull requests of a repository matching the label
+// and creator filters through the Search API. The content filter is applied to the
+// results as in fetchPullRequests. When the search matches more pull requests than
+// the Search API returns, errSearchResultLimit is returned before fetching them.
+func (c *Client) searchPullRequests(ctx context.Context, owner, repo string, labels []string, contentFilter string, creator string, dates DateFilter) ([]models.GitHubPR, error) {
+	var allPRs []models.GitHubPR
+	var nodeIDs []string
+
+	query := c.pullRequestSearchQuery(owner, repo, labels, creator) + dates.searchQualifiers()
+	opts := &github.SearchOptions{
+		Sort:        "updated",
+		Order:       "desc",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	total := 0
+
+	for {
+		var result *github.IssuesSearchResult
+		var resp *github.Response
+		err := c.limiter.call(ctx, func() (*github.Response, error) {
+			var err error
+			result, resp, err = c.client.Search.Issues(ctx, query, opts)
+			return resp, err
+		})
+		if err != nil {
+			return allPRs, fmt.Errorf("failed to search pull requests for %q: %w", query, err)
+		}
+		total = result.GetTotal()
+		if total > searchResultLimit {
+			return nil, errSearchResultLimit
+		}
+
+		for _, issue := range result.Issues {
+			if dates.closedBefore(issue.ClosedAt.GetTime()) {
+				continue
+			}
+
+			// Apply content filter if specified, leaving items for the comment search when enabled
+			var match *models.ContentMatch
+			if contentFilter != "" {
+				match = matchContent(contentFilter, issue.GetTitle(), issue.GetBody())
+				if match == nil && !c.searchComments {
+					continue
+				}
+			}
+
+			pr := convertSearchPR(issue, owner, repo)
+			pr.ContentMatch = match
+			allPRs = append(allPRs, pr)
+			nodeIDs = append(nodeIDs, issue.GetNodeID())
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	c.fillHeadBranches(ctx, allPRs, nodeIDs)
+
+	c.logger.Info("Fetched GitHub pull requests", "repo", fmt.Sprintf("%s/%s", owner, repo), "count", len(allPRs), "query", query, "total", total)
+	return allPRs, nil
+}
+
+// fillHeadBranches sets the head branch of pull requests found through the Search
+// API and adds the Jira keys in the branch names. nodeIDs holds the node ID of each
+// pull request. Pull requests whose branch cannot be looked up keep an empty branch.
+func (c *Client) fillHeadBranches(ctx context.Context, prs []models.GitHubPR, nodeIDs []string) {
+	for start := 0; start < len(prs); start += headBranchBatchSize {
+		end := start + headBranchBatchSize
+		if end > len(prs) {
+			end = len(prs)
+		}
+
+		var data struct {
+			Nodes []*struct {
+				ID          string `json:"id"`
+				HeadRefName string `json:"headRefName"`
+			} `json:"nodes"`
+		}
+		if err := c.graphQL(ctx, headBranchQuery, map[string]interface{}{"ids": nodeIDs[start:end]}, &data); err != nil {
+			c.logger.Warn("Failed to look up pull request branches, branch names are not correlated", "count", end-start, "error", err)
+			continue
+		}
+
+		branches := make(map[string]string, len(data.Nodes))
+		for _, node := range data.Nodes {
+			if node != nil {
+				branches[node.ID] = node.HeadRefName
+			}
+		}
+		for i := start; i < end; i++ {
+			branch := branches[nodeIDs[i]]
+			if branch == "" {
+				continue
+			}
+			prs[i].HeadBranch = branch
+			prs[i].JiraKeyRefs = mergeKeys(prs[i].JiraKeyRefs, correlation.ExtractBranchKeys(branch))
+		}
+	}
+}
+
+// pullRequestSearchQuery builds a search query for the pull requests of a repository.
+// Repeated label qualifiers require all labels, a comma-separated list matches any.
+func (c *Client) pullRequestSearchQuery(owner, repo string, labels []string, creator string) string {
+	parts := []string{"is:pr", fmt.Sprintf("repo:%s/%s", owner, repo)}
+
+	quoted := make([]string, 0, len(labels))
+	for _, label := range labels {
+		quoted = append(quoted, fmt.Sprintf("%q", label))
+	}
+	if c.matchAnyLabel {
+		if len(quoted) > 0 {
+			parts = append(parts, "label:"+strings.Join(quoted, ","))
+		}
+	} else {
+		for _, label := range quoted {
+			parts = append(parts, "label:"+label)
+		}
+	}
+
+	if creator != "" {
+		parts = append(parts, "author:"+creator)
+	}
+	return strings.Join(parts, " ")
+}
+
+// convertSearchPR converts a pull request returned by the issue search API to our model
+func convertSearchPR(issue *github.Issue, owner, repo string) models.GitHubPR {
+	mergeStatus := "unknown"
+	if issue.PullRequestLinks != nil && issue.PullRequestLinks.MergedAt != nil {
+		mergeStatus = "merged"
+	}
+
+	return models.GitHubPR{
+		Title:       issue.GetTitle(),
+		Number:      issue.GetNumber(),
+		State:       issue.GetState(),
+		Labels:      labelNames(issue.Labels),
+		Assignees:   userLogins(issue.Assignees),
+		CreatedDate: issue.GetCreatedAt().Time,
+		UpdatedDate: issue.GetUpdatedAt().Time,
+		ClosedDate:  issue.ClosedAt.GetTime(),
+		URL:         issue.GetHTMLURL(),
+		Repository:  fmt.Sprintf("%s/%s", owner, repo),
+		IsDraft:     issue.GetDraft(),
+		MergeStatus: mergeStatus,
+		Author:      issue.GetUser().GetLogin(),
+		JiraKeyRefs: correlation.ExtractKeys(issue.GetTitle(), issue.GetBody()),
+	}
+}
+
+// repoFromURL extracts the owner and repository from an API repository URL
+// such as https://api.github.com/repos/owner/repo
+func repoFromURL(url string) (string, string) {
+	parts := strings.Split(strings.TrimSuffix(url, "/"), "/")
+	if len(parts) < 2 {
+		return "", ""
+	}
+	return parts[len(parts)-2], parts[len(parts)-1]
+}
+
+// labelNames returns the names of GitHub labels
+func labelNames(labels []*github.Label) []string {
+	var names []string
+	for _, label := range labels {
+		names = append(names, label.GetName())
+	}
+	return names
+}
+
+// userLogins returns the logins of GitHub users
+func userLogins(users []*github.User) []string {
+	var logins []string
+	for _, user := range users {
+		logins = append(logins, user.GetLogin())
+	}
+	return logins
+}
@@ -13,8 +13,13 @@ import (
 
 // Client handles interactions with the Jira API
 type Client struct {
-	client *jiralib.Client
-	logger *slog.Logger
+	client       *jiralib.Client
+	authType     string
+	pageSize     int
+	maxIssues    int
+	fieldMapping config.JiraFieldMapping
+	linkTypes    []string
+	logger       *slog.Logger
 }
 
 // Custom transport for Bearer token authentication
@@ -48,45 +53,79 @@ func cloneRequest(req *http.Request) *http.Request {
 
 // NewClient creates a new Jira client
 func NewClient(cfg config.JiraConfig, logger *slog.Logger) (*Client, error) {
-	// Log the Jira URL being used (without credentials)
-	logger.Info("Creating Jira client", "url", cfg.URL, "auth_type", "Bearer Token")
+	authType := config.ResolveAuthType(cfg.AuthType, cfg.URL)
 
-	// Create a transport with Bearer token authentication
-	tp := &bearerAuthTransport{
-		RoundTripper: http.DefaultTransport,
-		Token:        cfg.APIToken,
-	}
+	// Log the Jira URL being used (without credentials)
+	logger.Info("Creating Jira client", "url", cfg.URL, "auth_type", authType)
 
 	// Log token length for debugging (don't log the actual token)
-	logger.Debug("Using PAT token for authentication", "token_length", len(cfg.APIToken))
+	logger.Debug("Using API token for authentication", "token_length", len(cfg.APIToken))
+
+	var httpClient *http.Client
+	switch authType {
+	case config.AuthTypeBasic:
+		// Atlassian Cloud API tokens are sent as email:token using Basic auth
+		tp := jiralib.BasicAuthTransport{
+			Username: cfg.Username,
+			Password: cfg.APIToken,
+		}
+		httpClient = tp.Client()
+	case config.AuthTypeBearer:
+		// Data Center and Server PATs are sent as a Bearer token
+		tp := &bearerAuthTransport{
+			RoundTripper: http.DefaultTransport,
+			Token:        cfg.APIToken,
+		}
+		httpClient = tp.Client()
+	default:
+		return nil, fmt.Errorf("unsupported Jira auth type: %s", authType)
+	}
 
-	// Create the client with the Bearer auth transport
-	client, err := jiralib.NewClient(tp.Client(), cfg.URL)
+	// Create the client with the selected auth transport
+	client, err := jiralib.NewClient(httpClient, cfg.URL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Jira client: %w", err)
 	}
 
 	return &Client{
-		client: client,
-		logger: logger,
+		client:       client,
+		authType:     authType,
+		pageSize:     cfg.PageSize,
+		maxIssues:    cfg.MaxIssues,
+		fieldMapping: cfg.FieldMapping,
+		linkTypes:    cfg.DependencyLinkTypes,
+		logger:       logger,
 	}, nil
 }
 
-// FetchIssues fetches issues from Jira based on the provided projects and JQL
-func (c *Client) FetchIssues(projects []string, jql string) ([]models.JiraIssue, error) {
-	var issues []models.JiraIssue
+// FetchOptions controls how issues are fetched from Jira
+type FetchOptions struct {
+	// Since restricts the search to issues updated at or after this time when set
+	Since time.Time
+	// AllowTruncated returns the first jira.max_issues issues instead of failing
+	// when the query matches more issues than the limit
+	AllowTruncated bool
+}
 
+// FetchResult holds the issues returned by FetchIssues
+type FetchResult struct {
+	Issues []models.JiraIssue
+	// Total is the number of issues matching the query as reported by Jira
+	Total int
+	// Truncated is set when fewer issues than Total were fetched
+	Truncated bool
+}
+
+// FetchIssues fetches issues from Jira based on the provided projects and JQL
+func (c *Client) FetchIssues(projects []string, jql string, opts FetchOptions) (*FetchResult, error) {
 	// Log the request with detailed information
 	baseURL := c.client.GetBaseURL()
 	c.logger.Info("Fetching issues from Jira", 
 		"projects", projects, 
 		"jql", jql, 
+		"since", opts.Since, 
 		"url", baseURL.String(),
-		"auth_method", "Bearer Token")
-
-	// Build JQL query
-	query := buildJQLQuery(projects, jql)
-	c.logger.Info("Fetching Jira issues", "jql", query)
+		"auth_method", c.authType)
 
 	// Test authentication first with a simple API call
 	c.logger.Debug("Testing Jira authentication")
@@ -104,27 +143,28 @@ func (c *Client) FetchIssues(projects []string, jql string) ([]models.JiraIssue,
 		c.logger.Info("Authentication successful", "username", myself.DisplayName)
 	}
 
-	// Define constants for pagination
-	const (
-		maxResultsPerPage = 100 // Reduced from 1000 to avoid potential performance issues
-		maxPages = 50          // Safety limit to prevent infinite loops
-	)
+	// Build JQL query, with the since time in the timezone Jira reads JQL dates in
+	var timeZone string
+	if myself != nil {
+		timeZone = myself.TimeZone
+	}
+	query := buildJQLQuery(projects, jql, jqlSince(opts.Since, timeZone, c.logger))
+	c.logger.Info("Fetching Jira issues", "jql", query)
+
+	// Resolve configured field names to field IDs
+	if err := c.resolveFieldMapping(); err != nil {
+		return nil, fmt.Errorf("failed to resolve Jira field mapping: %w", err)
+	}
 
 	// Initialize search options
 	options := &jiralib.SearchOptions{
-		MaxResults: maxResultsPerPage,
+		MaxResults: c.pageSize,
 		StartAt:    0,
-		Fields:     []string{
-			"summary", "status", "priority", "assignee", "reporter", "labels", 
-			"created", "updated", "description", "fixVersions", "watches", "issuetype",
-			"epic", "parent", "duedate", "customfield_10000", "customfield_10001", "customfield_10002", 
-			"customfield_10003", "customfield_10004", "customfield_10005", "customfield_10006", 
-			"customfield_10007", "customfield_10008", "customfield_10009", "customfield_10010",
-		},
+		Fields:     searchFields(c.fieldMapping),
 	}
 
-	// Implement pagination
-	totalFetched := 0
+	// Paginate using the total reported by the search response
+	result := &FetchResult{}
 	page := 1
 
 	for {
@@ -149,49 +189,91 @@ func (c *Client) FetchIssues(projects []string, jql string) ([]models.JiraIssue,
 			return nil, fmt.Errorf("failed to search Jira issues (status: %d): %w", statusCode, err)
 		}
 
-		// Convert and append issues
-		pageCount := len(jiraIssues)
-		totalFetched += pageCount
+		// Check the reported total against the safety limit before fetching more pages
+		if page == 1 {
+			result.Total = resp.Total
+			if result.Total > c.maxIssues {
+				if !opts.AllowTruncated {
+					return nil, fmt.Errorf("query matches %d issues which exceeds the limit of %d, raise jira.max_issues or pass --allow-truncated", result.Total, c.maxIssues)
+				}
+				c.logger.Warn("Query exceeds the issue limit, results will be truncated", 
+					"total", result.Total, 
+					"max_issues", c.maxIssues)
+			}
+		}
 
-		c.logger.Info("Fetched page of Jira issues", 
-			"page", page, 
-			"count", pageCount, 
-			"total_so_far", totalFetched)
+		pageCount := len(jiraIssues)
 
 		// Convert Jira issues to our model
 		for _, issue := range jiraIssues {
-			jiraIssue := convertJiraIssue(issue)
-			issues = append(issues, jiraIssue)
+			if len(result.Issues) >= c.maxIssues {
+				break
+			}
+			result.Issues = append(result.Issues, convertJiraIssue(issue, c.fieldMapping, c.linkTypes))
 		}
 
-		// Check if we've reached the end of results
-		if pageCount < options.MaxResults {
-			c.logger.Debug("Reached end of results", "total_fetched", totalFetched)
+		c.logger.Info("Fetched page of Jira issues", 
+			"page", page, 
+			"count", pageCount, 
+			"total_so_far", len(result.Issues), 
+			"total", result.Total)
+
+		// Jira may cap the page size below the requested value, so advance by what was
+		// returned and stop on an empty page to avoid looping forever
+		options.StartAt += pageCount
+		if pageCount == 0 || options.StartAt >= result.Total {
+			c.logger.Debug("Reached end of results", "total_fetched", len(result.Issues))
 			break
 		}
 
-		// Safety check to prevent infinite loops
-		if page >= maxPages {
-			c.logger.Warn("Reached maximum page limit", 
-				"max_pages", maxPages, 
-				"total_fetched", totalFetched)
+		if len(result.Issues) >= c.maxIssues {
+			c.logger.Warn("Reached maximum issue limit", 
+				"max_issues", c.maxIssues, 
+				"total_fetched", len(result.Issues), 
+				"total", result.Total)
 			break
 		}
 
-		// Move to next page
-		options.StartAt += pageCount
 		page++
 	}
 
+	result.Truncated = len(result.Issues) < result.Total
+
 	c.logger.Info("Completed fetching Jira issues", 
-		"total_count", len(issues), 
+		"total_count", len(result.Issues), 
+		"total", result.Total, 
+		"truncated", result.Truncated, 
 		"pages_fetched", page)
 
-	return issues, nil
+	return result, nil
+}
+
+// sinceOverlap widens the since time when the timezone of the Jira user profile is
+// unknown, to cover any difference between UTC and that timezone
+const sinceOverlap = 24 * time.Hour
+
+// jqlSince converts since into the timezone of the Jira user profile, in which Jira
+// reads JQL dates. When that timezone is unknown, since is converted to UTC and
+// moved back by sinceOverlap, fetching some issues twice rather than missing any.
+func jqlSince(since time.Time, timeZone string, logger *slog.Logger) time.Time {
+	if since.IsZero() {
+		return since
+	}
+	if timeZone != "" {
+		if loc, err := time.LoadLocation(timeZone); err == nil {
+			return since.In(loc)
+		}
+		logger.Warn("Unknown Jira profile timezone", "timezone", timeZone)
+	}
+	logger.Warn("Jira profile timezone unknown, widening the since time", "overlap", sinceOverlap)
+	return since.UTC().Add(-sinceOverlap)
 }
 
-// buildJQLQuery constructs a JQL query from the provided projects and additional JQL
-func buildJQLQuery(projects []string, additionalJQL string) string {
+// buildJQLQuery constructs a JQL query from the provided projects and additional JQL.
+// When since is set an "updated >=" clause is added ahead of the additional JQL so
+// that any trailing ORDER BY in it stays valid. since is formatted in its own location,
+// see jqlSince.
+func buildJQLQuery(projects []string, additionalJQL string, since time.Time) string {
 	var projectQuery string
 	if len(projects) == 1 {
 		projectQuery = fmt.Sprintf("project = \"%s\"", projects[0])
@@ -206,14 +288,19 @@ func buildJQLQuery(projects []string, additionalJQL string) string {
 		projectQuery += ")"
 	}
 
+	if !since.IsZero() {
+		projectQuery += fmt.Sprintf(" AND updated >= \"%s\"", since.Format("2006-01-02 15:04"))
+	}
+
 	if additionalJQL != "" {
 		return fmt.Sprintf("%s AND %s", projectQuery, additionalJQL)
 	}
 	return projectQuery
 }
 
-// convertJiraIssue converts a Jira issue to our model
-func convertJiraIssue(issue jiralib.Issue) models.JiraIssue {
+// convertJiraIssue converts a Jira issue to our model, reading roadmap fields from the given mapping
+// and dependencies from issue links of the given link types
+func convertJiraIssue(issue jiralib.Issue, fields config.JiraFieldMapping, linkTypes []string) models.JiraIssue {
 	jiraIssue := models.JiraIssue{
 		Key:         issue.Key,
 		Summary:     issue.Fields.Summary,
@@ -254,9 +341,8 @@ func convertJiraIssue(issue jiralib.Issue) models.JiraIssue {
 		jiraIssue.FixVersions = append(jiraIssue.FixVersions, version.Name)
 	}
 
-	// Set epic link if available
-	// This is typically stored in a custom field, commonly customfield_10008
-	if epicLink, ok := issue.Fields.Unknowns["customfield_10008"].(string); ok && epicLink != "" {
+	// Set epic link from the mapped field, falling back to the "epic" field
+	if epicLink := fieldString(issue, fields.EpicLink); epicLink != "" {
 		jiraIssue.EpicLink = epicLink
 	}
 	epicLink, ok := issue.Fields.Unknowns["epic"]
@@ -267,58 +353,44 @@ func convertJiraIssue(issue jiralib.Issue) models.JiraIssue {
 	}
 
 	// Set team if available
-	team, ok := issue.Fields.Unknowns["team"]
-	if ok && team != nil {
-		if teamStr, ok := team.(string); ok {
-			jiraIssue.Team = teamStr
-		}
-	}
+	jiraIssue.Team = fieldString(issue, fields.Team)
 
-	// Extract roadmap-related fields from custom fields
-	
-	// PlannedStartDate - typically stored in a custom field
-	if startDateStr, ok := issue.Fields.Unknowns["customfield_10001"].(string); ok && startDateStr != "" {
-		if startDate, err := time.Parse("2006-01-02", startDateStr); err == nil {
-			jiraIssue.PlannedStartDate = &startDate
-		}
-	}
-	
-	// PlannedEndDate - can be the due date or a custom field
+	// Extract roadmap-related fields from the mapped custom fields
+
+	// PlannedStartDate
+	jiraIssue.PlannedStartDate = fieldDate(issue, fields.PlannedStartDate)
+
+	// PlannedEndDate - the due date takes precedence over the mapped field
 	if !time.Time(issue.Fields.Duedate).IsZero() {
 		dueDate := time.Time(issue.Fields.Duedate)
 		jiraIssue.PlannedEndDate = &dueDate
-	} else if endDateStr, ok := issue.Fields.Unknowns["customfield_10002"].(string); ok && endDateStr != "" {
-		if endDate, err := time.Parse("2006-01-02", endDateStr); err == nil {
-			jiraIssue.PlannedEndDate = &endDate
-		}
-	}
-	
-	// Theme - typically stored in a custom field
-	if theme, ok := issue.Fields.Unknowns["customfield_10003"].(string); ok {
-		jiraIssue.Theme = theme
-	}
-	
-	// Initiative - typically stored in a custom field
-	if initiative, ok := issue.Fields.Unknowns["customfield_10004"].(string); ok {
-		jiraIssue.Initiative = initiative
+	} else {
+		jiraIssue.PlannedEndDate = fieldDate(issue, fields.PlannedEndDate)
 	}
-	
-	// Dependencies - can be extracted from links or a custom field
-	if dependencies, ok := issue.Fields.Unknowns["customfield_10005"].([]interface{}); ok {
-		for _, dep := range dependencies {
-			if depStr, ok := dep.(string); ok {
-				jiraIssue.Dependencies = append(jiraIssue.Dependencies, depStr)
-			}
-		}
+
+	// Theme
+	jiraIssue.Theme = fieldString(issue, fields.Theme)
+
+	// Initiative
+	jiraIssue.Initiative = fieldString(issue, fields.Initiative)
+
+	// Dependencies - taken from issue links, plus any keys listed in the mapped field
+	jiraIssue.Dependencies = linkDependencies(issue, linkTypes)
+	for _, key := range fieldStrings(issue, fields.Dependencies) {
+		jiraIssue.Dependencies = append(jiraIssue.Dependencies, models.Dependency{
+			Key:       key,
+			Direction: models.DependencyInward,
+			Relation:  "depends on",
+		})
 	}
-	
-	// PriorityScore - typically stored in a custom field
-	if priorityScore, ok := issue.Fields.Unknowns["customfield_10006"].(float64); ok {
+
+	// PriorityScore
+	if priorityScore, ok := fieldNumber(issue, fields.PriorityScore); ok {
 		jiraIssue.PriorityScore = int(priorityScore)
 	}
-	
+
 	// RoadmapStatus - can be derived from status or stored in a custom field
-	if roadmapStatus, ok := issue.Fields.Unknowns["customfield_10007"].(string); ok {
+	if roadmapStatus := fieldString(issue, fields.RoadmapStatus); roadmapStatus != "" {
 		jiraIssue.RoadmapStatus = roadmapStatus
 	} else {
 		// Derive from status
@@ -335,19 +407,18 @@ func convertJiraIssue(issue jiralib.Issue) models.JiraIssue {
 			jiraIssue.RoadmapStatus = "Planned"
 		}
 	}
-	
-	// Milestone - typically stored in a custom field or can be a fix version
-	if milestone, ok := issue.Fields.Unknowns["customfield_10009"].(string); ok {
+
+	// Milestone - stored in a custom field or falls back to the first fix version
+	if milestone := fieldString(issue, fields.Milestone); milestone != "" {
 		jiraIssue.Milestone = milestone
 	} else if len(jiraIssue.FixVersions) > 0 {
 		jiraIssue.Milestone = jiraIssue.FixVersions[0]
 	}
-	
-	// Quarter - typically stored in a custom field
-	if quarter, ok := issue.Fields.Unknowns["customfield_10010"].(string); ok {
+
+	// Quarter - stored in a custom field or derived from the planned start date
+	if quarter := fieldString(issue, fields.Quarter); quarter != "" {
 		jiraIssue.Quarter = quarter
 	} else if jiraIssue.PlannedStartDate != nil {
-		// Derive quarter from planned start date
 		quarterNum := (int(jiraIssue.PlannedStartDate.Month())-1)/3 + 1
 		jiraIssue.Quarter = fmt.Sprintf("Q%d %d", quarterNum, jiraIssue.PlannedStartDate.Year())
 	}
@@ -0,0 +1,330 @@
+package jira
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	jiralib "github.com/andygrunwald/go-jira"
+	"github.com/krzko/jiragitfluence/internal/config"
+	"github.com/krzko/jiragitfluence/pkg/models"
+)
+
+// Field describes a Jira field as reported by /rest/api/2/field
+type Field struct {
+	ID     string
+	Name   string
+	Type   string
+	Custom bool
+}
+
+// ListFields returns all fields known to the Jira instance, sorted by name
+func (c *Client) ListFields() ([]Field, error) {
+	c.logger.Info("Fetching Jira fields")
+
+	jiraFields, resp, err := c.client.Field.GetList()
+	if err != nil {
+		statusCode := -1
+		if resp != nil {
+			statusCode = resp.StatusCode
+		}
+		return nil, fmt.Errorf("failed to list Jira fields (status: %d): %w", statusCode, err)
+	}
+
+	fields := make([]Field, 0, len(jiraFields))
+	for _, f := range jiraFields {
+		fields = append(fields, Field{
+			ID:     f.ID,
+			Name:   f.Name,
+			Type:   f.Schema.Type,
+			Custom: f.Custom,
+		})
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		return strings.ToLower(fields[i].Name) < strings.ToLower(fields[j].Name)
+	})
+
+	c.logger.Debug("Fetched Jira fields", "count", len(fields))
+	return fields, nil
+}
+
+// fieldIDs are the field IDs used as-is in a field mapping without a name lookup,
+// besides custom field IDs. Team is read from the "team" field by default.
+var fieldIDs = map[string]bool{
+	"summary": true, "status": true, "priority": true, "assignee": true, "reporter": true,
+	"labels": true, "components": true, "fixVersions": true, "versions": true,
+	"duedate": true, "parent": true, "epic": true, "environment": true, "team": true,
+}
+
+// isFieldID reports whether a field mapping value is a field ID rather than a name
+func isFieldID(value string) bool {
+	return strings.HasPrefix(value, "customfield_") || fieldIDs[value]
+}
+
+// resolveFieldMapping translates field names in the configured mapping into field IDs.
+// Values that already are field IDs are used as-is, so the field list is only
+// requested when at least one entry is a name.
+func (c *Client) resolveFieldMapping() error {
+	needsLookup := false
+	for _, value := range c.fieldMapping.Fields() {
+		if value != "" && !isFieldID(value) {
+			needsLookup = true
+			break
+		}
+	}
+	if !needsLookup {
+		return nil
+	}
+
+	fields, err := c.ListFields()
+	if err != nil {
+		return err
+	}
+
+	ids := make(map[string]bool, len(fields))
+	byName := make(map[string]string, len(fields))
+	for _, f := range fields {
+		ids[f.ID] = true
+		byName[strings.ToLower(f.Name)] = f.ID
+	}
+
+	resolve := func(value *string) {
+		if *value == "" || ids[*value] {
+			return
+		}
+		if id, ok := byName[strings.ToLower(*value)]; ok {
+			c.logger.Debug("Resolved Jira field name", "name", *value, "id", id)
+			*value = id
+			return
+		}
+		c.logger.Debug("Jira field not found on instance, using as-is", "field", *value)
+	}
+
+	m := &c.fieldMapping
+	resolve(&m.PlannedStartDate)
+	resolve(&m.PlannedEndDate)
+	resolve(&m.Theme)
+	resolve(&m.Initiative)
+	resolve(&m.Dependencies)
+	resolve(&m.PriorityScore)
+	resolve(&m.RoadmapStatus)
+	resolve(&m.EpicLink)
+	resolve(&m.Milestone)
+	resolve(&m.Quarter)
+	resolve(&m.Team)
+
+	return nil
+}
+
+// searchFields returns the list of fields to request from the search API
+func searchFields(mapping config.JiraFieldMapping) []string {
+	fields := []string{
+		"summary", "status", "priority", "assignee", "reporter", "labels",
+		"created", "updated", "description", "fixVersions", "watches", "issuetype",
+		"epic", "parent", "duedate", "issuelinks",
+	}
+
+	seen := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		seen[f] = true
+	}
+
+	mapped := mapping.Fields()
+	keys := make([]string, 0, len(mapped))
+	for key := range mapped {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if f := mapped[key]; f != "" && !seen[f] {
+			seen[f] = true
+			fields = append(fields, f)
+		}
+	}
+
+	return fields
+}
+
+// fieldValue returns the raw value of a field. Fields that go-jira parses into
+// typed issue fields, such as duedate and parent, are converted back into the
+// shape of their raw value, so they can be mapped like custom fields.
+func fieldValue(issue jiralib.Issue, id string) interface{} {
+	if id == "" || issue.Fields == nil {
+		return nil
+	}
+	if value, ok := issue.Fields.Unknowns[id]; ok {
+		return value
+	}
+
+	f := issue.Fields
+	switch id {
+	case "summary":
+		return f.Summary
+	case "environment":
+		return f.Environment
+	case "duedate":
+		if due := time.Time(f.Duedate); !due.IsZero() {
+			return due.Format("2006-01-02")
+		}
+	case "parent":
+		if f.Parent != nil {
+			return f.Parent.Key
+		}
+	case "epic":
+		if f.Epic != nil {
+			return f.Epic.Key
+		}
+	case "status":
+		if f.Status != nil {
+			return f.Status.Name
+		}
+	case "priority":
+		if f.Priority != nil {
+			return f.Priority.Name
+		}
+	case "assignee":
+		if f.Assignee != nil {
+			return f.Assignee.DisplayName
+		}
+	case "reporter":
+		if f.Reporter != nil {
+			return f.Reporter.DisplayName
+		}
+	case "labels":
+		values := make([]interface{}, 0, len(f.Labels))
+		for _, label := range f.Labels {
+			values = append(values, label)
+		}
+		return values
+	case "components":
+		values := make([]interface{}, 0, len(f.Components))
+		for _, component := range f.Components {
+			values = append(values, component.Name)
+		}
+		return values
+	case "fixVersions":
+		values := make([]interface{}, 0, len(f.FixVersions))
+		for _, version := range f.FixVersions {
+			values = append(values, version.Name)
+		}
+		return values
+	case "versions":
+		values := make([]interface{}, 0, len(f.AffectsVersions))
+		for _, version := range f.AffectsVersions {
+			values = append(values, version.Name)
+		}
+		return values
+	}
+	return nil
+}
+
+// fieldString reads a field as a string, unwrapping option, user and array values
+func fieldString(issue jiralib.Issue, id string) string {
+	return stringValue(fieldValue(issue, id))
+}
+
+// stringValue converts a raw Jira field value into a string
+func stringValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return fmt.Sprintf("%g", v)
+	case map[string]interface{}:
+		for _, key := range []string{"value", "name", "displayName", "title", "key"} {
+			if s, ok := v[key].(string); ok && s != "" {
+				return s
+			}
+		}
+	case []interface{}:
+		if len(v) > 0 {
+			return stringValue(v[0])
+		}
+	}
+	return ""
+}
+
+// fieldStrings reads a field as a list of strings
+func fieldStrings(issue jiralib.Issue, id string) []string {
+	var values []string
+	switch v := fieldValue(issue, id).(type) {
+	case []interface{}:
+		for _, item := range v {
+			if s := stringValue(item); s != "" {
+				values = append(values, s)
+			}
+		}
+	default:
+		if s := stringValue(v); s != "" {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// fieldNumber reads a numeric field
+func fieldNumber(issue jiralib.Issue, id string) (float64, bool) {
+	n, ok := fieldValue(issue, id).(float64)
+	return n, ok
+}
+
+// fieldDate reads a date or datetime field
+func fieldDate(issue jiralib.Issue, id string) *time.Time {
+	s := fieldString(issue, id)
+	if s == "" {
+		return nil
+	}
+
+	for _, layout := range []string{"2006-01-02", "2006-01-02T15:04:05.000-0700", time.RFC3339} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// linkDependencies converts issue links of the given types into dependencies.
+// Link types are matched case-insensitively against the link type name.
+func linkDependencies(issue jiralib.Issue, linkTypes []string) []models.Dependency {
+	if len(issue.Fields.IssueLinks) == 0 || len(linkTypes) == 0 {
+		return nil
+	}
+
+	wanted := make(map[string]bool, len(linkTypes))
+	for _, t := range linkTypes {
+		wanted[strings.ToLower(t)] = true
+	}
+
+	var deps []models.Dependency
+	for _, link := range issue.Fields.IssueLinks {
+		if link == nil || !wanted[strings.ToLower(link.Type.Name)] {
+			continue
+		}
+
+		switch {
+		case link.OutwardIssue != nil:
+			deps = append(deps, models.Dependency{
+				Key:       link.OutwardIssue.Key,
+				Type:      link.Type.Name,
+				Direction: models.DependencyOutward,
+				Relation:  link.Type.Outward,
+				Outward:   link.Type.Outward,
+				Inward:    link.Type.Inward,
+			})
+		case link.InwardIssue != nil:
+			deps = append(deps, models.Dependency{
+				Key:       link.InwardIssue.Key,
+				Type:      link.Type.Name,
+				Direction: models.DependencyInward,
+				Relation:  link.Type.Inward,
+				Outward:   link.Type.Outward,
+				Inward:    link.Type.Inward,
+			})
+		}
+	}
+
+	return deps
+}
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // AggregatedData represents the combined data from Jira and GitHub
 type AggregatedData struct {
@@ -33,11 +36,61 @@ type JiraIssue struct {
 	PlannedEndDate   *time.Time   `json:"plannedEndDate,omitempty"`
 	Theme            string       `json:"theme,omitempty"`
 	Initiative       string       `json:"initiative,omitempty"`
-	Dependencies     []string     `json:"dependencies,omitempty"` // List of issue keys this issue depends on
+	Dependencies     []Dependency `json:"dependencies,omitempty"` // Linked issues with their link type and direction
 	PriorityScore    int          `json:"priorityScore,omitempty"` // Numeric priority (1-100)
 	RoadmapStatus    string       `json:"roadmapStatus,omitempty"` // Planning status (e.g., "Planned", "In Progress", "Completed")
 	Milestone        string       `json:"milestone,omitempty"`
 	Quarter          string       `json:"quarter,omitempty"` // Which quarter this is planned for (e.g., "Q1 2025")
+	// GitHub items referencing this issue, set by correlation
+	LinkedPRs          []GitHubRef `json:"linkedPRs,omitempty"`
+	LinkedGitHubIssues []GitHubRef `json:"linkedGitHubIssues,omitempty"`
+}
+
+// GitHubRef is a reference from a Jira issue to a GitHub issue or pull request
+type GitHubRef struct {
+	Repository string `json:"repository"`
+	Number     int    `json:"number"`
+	Title      string `json:"title"`
+	URL        string `json:"url"`
+	State      string `json:"state"`
+	Merged     bool   `json:"merged,omitempty"`
+	IsDraft    bool   `json:"isDraft,omitempty"`
+}
+
+// Dependency directions relative to the issue holding the dependency
+const (
+	// DependencyInward means the linked issue acts on this issue (e.g., "is blocked by")
+	DependencyInward = "inward"
+	// DependencyOutward means this issue acts on the linked issue (e.g., "blocks")
+	DependencyOutward = "outward"
+)
+
+// Dependency represents a link from a Jira issue to another issue
+type Dependency struct {
+	Key       string `json:"key"`
+	Type      string `json:"type,omitempty"`      // Link type name (e.g., "Blocks")
+	Direction string `json:"direction,omitempty"` // DependencyInward or DependencyOutward
+	Relation  string `json:"relation,omitempty"`  // Relation as worded by Jira (e.g., "is blocked by")
+	Outward   string `json:"outward,omitempty"`   // Outward wording of the link type (e.g., "blocks")
+	Inward    string `json:"inward,omitempty"`    // Inward wording of the link type (e.g., "is blocked by")
+}
+
+// UnmarshalJSON accepts both the structured form and the plain issue keys
+// written by earlier versions, which are read as inward dependencies
+func (d *Dependency) UnmarshalJSON(data []byte) error {
+	var key string
+	if err := json.Unmarshal(data, &key); err == nil {
+		*d = Dependency{Key: key, Direction: DependencyInward, Relation: "depends on"}
+		return nil
+	}
+
+	type dependency Dependency
+	var dep dependency
+	if err := json.Unmarshal(data, &dep); err != nil {
+		return err
+	}
+	*d = Dependency(dep)
+	return nil
 }
 
 // GitHubIssue represents a GitHub issue
@@ -49,6 +102,7 @@ type GitHubIssue struct {
 	Assignees        []string     `json:"assignees"`
 	CreatedDate      time.Time    `json:"createdDate"`
 	UpdatedDate      time.Time    `json:"updatedDate"`
+	ClosedDate       *time.Time   `json:"closedDate,omitempty"`
 	URL              string       `json:"url"`
 	Repository       string       `json:"repository"`
 	// Roadmap planning fields
@@ -60,7 +114,17 @@ type GitHubIssue struct {
 	PriorityScore    int          `json:"priorityScore,omitempty"` // Numeric priority (1-100)
 	RoadmapStatus    string       `json:"roadmapStatus,omitempty"` // Planning status
 	Milestone        string       `json:"milestone,omitempty"`
+	MilestoneDueDate *time.Time   `json:"milestoneDueDate,omitempty"`
 	Quarter          string       `json:"quarter,omitempty"` // Which quarter this is planned for
+	JiraKeyRefs      []string     `json:"jiraKeyRefs,omitempty"`    // Jira-style keys found in the title and body
+	LinkedJiraKeys   []string     `json:"linkedJiraKeys,omitempty"` // Keys of fetched Jira issues this issue references
+	ContentMatch     *ContentMatch `json:"contentMatch,omitempty"`  // Where the content filter matched
+}
+
+// ContentMatch records where the content filter matched a GitHub issue or pull request
+type ContentMatch struct {
+	Source  string `json:"source"`  // title, body, or the timeline item, e.g. "comment by octocat"
+	Snippet string `json:"snippet"` // Text surrounding the match
 }
 
 // GitHubPR represents a GitHub pull request
@@ -72,10 +136,32 @@ type GitHubPR struct {
 	Assignees   []string  `json:"assignees"`
 	CreatedDate time.Time `json:"createdDate"`
 	UpdatedDate time.Time `json:"updatedDate"`
+	ClosedDate  *time.Time `json:"closedDate,omitempty"`
 	URL         string    `json:"url"`
 	Repository  string    `json:"repository"`
 	IsDraft     bool      `json:"isDraft"`
 	MergeStatus string    `json:"mergeStatus"`
+	HeadBranch  string    `json:"headBranch,omitempty"`
+	Author      string    `json:"author,omitempty"`
+	// Review, CI and commit details, set when PR enrichment is enabled
+	BaseBranch         string     `json:"baseBranch,omitempty"`
+	ReviewDecision     string     `json:"reviewDecision,omitempty"` // approved, changes_requested or review_required
+	Reviewers          []string   `json:"reviewers,omitempty"`      // Users who submitted a review
+	RequestedReviewers []string   `json:"requestedReviewers,omitempty"`
+	CIStatus           string     `json:"ciStatus,omitempty"` // Combined check and status state of the head commit (success, failure, pending, error)
+	MergedAt           *time.Time `json:"mergedAt,omitempty"`
+	Additions          int        `json:"additions,omitempty"`
+	Deletions          int        `json:"deletions,omitempty"`
+	ClosingIssues      []string   `json:"closingIssues,omitempty"` // Issues closed by merging, as owner/repo#number
+	// Roadmap planning fields, read from Projects v2 fields by the graphql backend
+	PlannedStartDate *time.Time `json:"plannedStartDate,omitempty"`
+	PlannedEndDate   *time.Time `json:"plannedEndDate,omitempty"`
+	Milestone        string     `json:"milestone,omitempty"`
+	Theme            string     `json:"theme,omitempty"`
+	// Jira references
+	JiraKeyRefs    []string `json:"jiraKeyRefs,omitempty"`    // Jira-style keys found in the title, body and branch name
+	LinkedJiraKeys []string `json:"linkedJiraKeys,omitempty"` // Keys of fetched Jira issues this PR references
+	ContentMatch   *ContentMatch `json:"contentMatch,omitempty"` // Where the content filter matched
 }
 
 // Metadata contains information about the data collection
@@ -83,8 +169,19 @@ type Metadata struct {
 	FetchTime          time.Time `json:"fetchTime"`
 	JiraProjects       []string  `json:"jiraProjects"`
 	GitHubRepos        []string  `json:"githubRepos"`
+	GitHubRepoSpecs    []string  `json:"githubRepoSpecs,omitempty"`   // Repository specs as given (owner/repo, org:, topic: or search queries)
+	GitHubFailedRepos  []string  `json:"githubFailedRepos,omitempty"` // Repositories skipped because they could not be fetched
+	GitHubPartialRepos []string  `json:"githubPartialRepos,omitempty"` // Repositories fetched partially before the rate limit wait budget ran out
+	GitHubIncomplete   bool      `json:"githubIncomplete,omitempty"`  // Set when the rate limit wait budget ran out before all data was fetched
 	JiraJQL            string    `json:"jiraJql,omitempty"`
+	JiraTotal          int       `json:"jiraTotal,omitempty"`     // Number of issues matching the query as reported by Jira
+	JiraTruncated      bool      `json:"jiraTruncated,omitempty"` // Set when fewer Jira issues than JiraTotal were fetched
+	JiraUpdatedAfter   *time.Time `json:"jiraUpdatedAfter,omitempty"` // Jira issues updated before this time were not fetched
+	JiraUpsertOnly     bool       `json:"jiraUpsertOnly,omitempty"`    // Set when issues were merged into an earlier snapshot, which keeps issues deleted in Jira or no longer matching the query
+	JiraFullFetchTime  *time.Time `json:"jiraFullFetchTime,omitempty"` // Time of the last fetch that replaced the whole snapshot
 	GitHubLabels       []string  `json:"githubLabels,omitempty"`
+	GitHubUpdatedAfter *time.Time `json:"githubUpdatedAfter,omitempty"` // GitHub items updated before this time were not fetched
+	GitHubClosedAfter  *time.Time `json:"githubClosedAfter,omitempty"`  // GitHub items closed before this time were not fetched
 	GitHubContentFilter string    `json:"githubContentFilter,omitempty"`
 	GitHubCreator      string    `json:"githubCreator,omitempty"`
 	VersionLabel       string    `json:"versionLabel,omitempty"`